@@ -0,0 +1,54 @@
+// Package chainctors has a type with several constructors, which fz.Chain
+// chooses among at run time to create the chain's target.
+package chainctors
+
+import (
+	"errors"
+	"strings"
+)
+
+// Buffer is a bounded byte buffer.
+type Buffer struct {
+	data  []byte
+	limit int
+}
+
+func NewBuffer() *Buffer {
+	return &Buffer{limit: 64}
+}
+
+func NewBufferSize(limit int) *Buffer {
+	if limit < 0 {
+		limit = 0
+	}
+	return &Buffer{limit: limit}
+}
+
+func FromBytes(b []byte) (*Buffer, error) {
+	if len(b) > 64 {
+		return nil, errors.New("too long")
+	}
+	return &Buffer{data: append([]byte(nil), b...), limit: 64}, nil
+}
+
+func Parse(s string) (*Buffer, error) {
+	return FromBytes([]byte(strings.TrimSpace(s)))
+}
+
+// NewBufferValue returns a Buffer rather than a *Buffer, so it is not
+// one of the constructors chosen among to create a *Buffer target.
+func NewBufferValue() Buffer {
+	return Buffer{limit: 64}
+}
+
+func (b *Buffer) Write(p []byte) (int, error) {
+	if len(b.data)+len(p) > b.limit {
+		return 0, errors.New("full")
+	}
+	b.data = append(b.data, p...)
+	return len(p), nil
+}
+
+func (b *Buffer) Len() int {
+	return len(b.data)
+}
@@ -0,0 +1,60 @@
+// Package fallbackexample contains types without constructors, which can be used
+// as chain targets via 'fzgen -chain -ctorfallback=zero' or '-ctorfallback=fill'.
+package fallbackexample
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Counter counts occurrences of keys. The zero value is ready to use.
+type Counter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *Counter) Add(key string, n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[key] += n
+}
+
+func (c *Counter) Get(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[key]
+}
+
+// Point has exported fields that fz.Fill can populate.
+type Point struct {
+	X, Y int
+}
+
+func (p Point) Add(q Point) Point {
+	return Point{X: p.X + q.X, Y: p.Y + q.Y}
+}
+
+func (p *Point) Scale(k int) {
+	p.X *= k
+	p.Y *= k
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d,%d)", p.X, p.Y)
+}
+
+// ManhattanDistance is a package-level function that takes a Point,
+// which means it is included as a step in a chain for Point.
+func ManhattanDistance(p, q *Point) int {
+	return abs(p.X-q.X) + abs(p.Y-q.Y)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,66 @@
+// Package chainfuncs contains package-level functions that take a chain's target type,
+// which are included as steps in the chain along with the target's methods.
+package chainfuncs
+
+import "math/bits"
+
+// Bitmap is a small set of uint8 values.
+type Bitmap struct {
+	words [4]uint64
+}
+
+func NewBitmap() *Bitmap {
+	return &Bitmap{}
+}
+
+func (b *Bitmap) Set(i uint8) {
+	b.words[i/64] |= 1 << (i % 64)
+}
+
+func (b *Bitmap) Has(i uint8) bool {
+	return b.words[i/64]&(1<<(i%64)) != 0
+}
+
+// And clears the bits in b that are not set in other. In a chain, other can be
+// the return value of an earlier step such as NewBitmap.
+func (b *Bitmap) And(other *Bitmap) {
+	for i := range b.words {
+		b.words[i] &= other.words[i]
+	}
+}
+
+// Merge sets the bits in dst that are set in src.
+func Merge(dst, src *Bitmap) {
+	for i := range dst.words {
+		dst.words[i] |= src.words[i]
+	}
+}
+
+// Count takes a Bitmap by value.
+func Count(b Bitmap) int {
+	var n int
+	for _, w := range b.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// HasAll takes the Bitmap after another parameter.
+func HasAll(values []uint8, b *Bitmap) bool {
+	for _, v := range values {
+		if !b.Has(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Union only takes Bitmaps as a variadic parameter, so the chain's target is not passed to it.
+// However, it returns a Bitmap, so it is a step whose return value can be used by later steps.
+func Union(bitmaps ...*Bitmap) *Bitmap {
+	result := NewBitmap()
+	for _, b := range bitmaps {
+		Merge(result, b)
+	}
+	return result
+}
@@ -0,0 +1,81 @@
+// Package invariants has types with methods that check their consistency,
+// such as Validate and Len, which fzgen calls after each step of a chain.
+package invariants
+
+import (
+	"errors"
+	"sort"
+)
+
+// Ring is a fixed-size ring buffer of bytes.
+type Ring struct {
+	buf        []byte
+	head, size int
+}
+
+func NewRing(n uint8) *Ring {
+	return &Ring{buf: make([]byte, int(n)+1)}
+}
+
+func (r *Ring) Push(b byte) {
+	r.buf[(r.head+r.size)%len(r.buf)] = b
+	if r.size < len(r.buf) {
+		r.size++
+	} else {
+		r.head = (r.head + 1) % len(r.buf)
+	}
+}
+
+func (r *Ring) Pop() (byte, bool) {
+	if r.size == 0 {
+		return 0, false
+	}
+	b := r.buf[r.head]
+	r.head = (r.head + 1) % len(r.buf)
+	r.size--
+	return b, true
+}
+
+// Len returns the number of bytes in the ring.
+func (r *Ring) Len() int {
+	return r.size
+}
+
+// Validate reports an error if the ring is internally inconsistent.
+func (r *Ring) Validate() error {
+	if r.head < 0 || r.head >= len(r.buf) || r.size > len(r.buf) {
+		return errors.New("ring out of bounds")
+	}
+	return nil
+}
+
+// SortedSet is a set of ints kept in a sorted slice.
+type SortedSet struct {
+	elems []int
+}
+
+func NewSortedSet() *SortedSet {
+	return &SortedSet{}
+}
+
+func (s *SortedSet) Insert(x int) {
+	i := sort.SearchInts(s.elems, x)
+	if i < len(s.elems) && s.elems[i] == x {
+		return
+	}
+	s.elems = append(s.elems, 0)
+	copy(s.elems[i+1:], s.elems[i:])
+	s.elems[i] = x
+}
+
+// CheckInvariants panics if the set is not sorted.
+func (s *SortedSet) CheckInvariants() {
+	if !sort.IntsAreSorted(s.elems) {
+		panic("set not sorted")
+	}
+}
+
+// Sorted reports whether the set is sorted.
+func (s *SortedSet) Sorted() bool {
+	return sort.IntsAreSorted(s.elems)
+}
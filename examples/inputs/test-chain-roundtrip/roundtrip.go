@@ -0,0 +1,157 @@
+// Package roundtrip has types that implement several roundtrip pairs,
+// such as MarshalText and UnmarshalText, which are checked after fz.Chain
+// runs the steps for a chain's target.
+package roundtrip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version. It implements encoding.TextMarshaler, encoding.TextUnmarshaler,
+// encoding.TextAppender, json.Marshaler, json.Unmarshaler, and fmt.Stringer along with Parse.
+type Version struct {
+	Major, Minor, Patch uint16
+}
+
+func NewVersion(major, minor, patch uint16) Version {
+	return Version{major, minor, patch}
+}
+
+func Parse(s string) (Version, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+	var nums [3]uint16
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 16)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid version %q: %v", s, err)
+		}
+		nums[i] = uint16(n)
+	}
+	return Version{nums[0], nums[1], nums[2]}, nil
+}
+
+func (v *Version) Bump(part uint8) {
+	switch part % 3 {
+	case 0:
+		v.Major++
+		v.Minor, v.Patch = 0, 0
+	case 1:
+		v.Minor++
+		v.Patch = 0
+	case 2:
+		v.Patch++
+	}
+}
+
+func (v Version) Less(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Patch < other.Patch
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+func (v Version) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v Version) AppendText(b []byte) ([]byte, error) {
+	return append(b, v.String()...), nil
+}
+
+func (v *Version) UnmarshalText(b []byte) error {
+	parsed, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+func (v Version) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(v.String())), nil
+}
+
+func (v *Version) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// Flags is a set of flags. It implements encoding.BinaryMarshaler, encoding.BinaryUnmarshaler,
+// encoding.BinaryAppender, gob.GobEncoder, and gob.GobDecoder, and has AppendCompact
+// along with ParseCompact.
+type Flags struct {
+	bits uint64
+}
+
+func NewFlags() *Flags {
+	return &Flags{}
+}
+
+func ParseCompact(b []byte) (*Flags, error) {
+	bits, n := binary.Uvarint(b)
+	if n <= 0 || n != len(b) {
+		return nil, errors.New("invalid compact flags")
+	}
+	return &Flags{bits}, nil
+}
+
+func (f *Flags) Set(i uint8) {
+	f.bits |= 1 << (i % 64)
+}
+
+func (f *Flags) Clear(i uint8) {
+	f.bits &^= 1 << (i % 64)
+}
+
+func (f *Flags) Has(i uint8) bool {
+	return f.bits&(1<<(i%64)) != 0
+}
+
+func (f *Flags) AppendCompact(b []byte) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], f.bits)
+	return append(b, buf[:n]...)
+}
+
+func (f *Flags) MarshalBinary() ([]byte, error) {
+	return f.AppendBinary(nil)
+}
+
+func (f *Flags) AppendBinary(b []byte) ([]byte, error) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], f.bits)
+	return append(b, buf[:]...), nil
+}
+
+func (f *Flags) UnmarshalBinary(b []byte) error {
+	if len(b) != 8 {
+		return errors.New("invalid flags")
+	}
+	f.bits = binary.BigEndian.Uint64(b)
+	return nil
+}
+
+func (f *Flags) GobEncode() ([]byte, error) {
+	return f.MarshalBinary()
+}
+
+func (f *Flags) GobDecode(b []byte) error {
+	return f.UnmarshalBinary(b)
+}
@@ -0,0 +1,103 @@
+// Package intset is a set of small non-negative integers backed by a bitmap,
+// which fzgen compares against the simpler implementation in the sliceset
+// directory via differential wrappers.
+package intset
+
+import (
+	"errors"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Set is a set of non-negative integers.
+type Set struct {
+	words []uint64
+}
+
+// New returns a Set with capacity for integers up to max.
+func New(max int) (*Set, error) {
+	if max < 0 || max > 1<<16 {
+		return nil, errors.New("max out of range")
+	}
+	return &Set{words: make([]uint64, max/64+1)}, nil
+}
+
+// Add adds x to the set, and reports whether it was added.
+func (s *Set) Add(x int) bool {
+	if x < 0 || x/64 >= len(s.words) || s.Has(x) {
+		return false
+	}
+	s.words[x/64] |= 1 << (x % 64)
+	return true
+}
+
+// Has reports whether x is in the set.
+func (s *Set) Has(x int) bool {
+	return x >= 0 && x/64 < len(s.words) && s.words[x/64]&(1<<(x%64)) != 0
+}
+
+// Len returns the number of integers in the set.
+func (s *Set) Len() int {
+	n := 0
+	for _, w := range s.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// AddAll adds the integers in xs to the set.
+func (s *Set) AddAll(xs ...int) {
+	for _, x := range xs {
+		s.Add(x)
+	}
+}
+
+// Elems returns the integers in the set in increasing order.
+func (s *Set) Elems() []int {
+	var elems []int
+	for i, w := range s.words {
+		for w != 0 {
+			elems = append(elems, i*64+bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+	return elems
+}
+
+// Parse parses a comma-separated list of integers.
+func Parse(s string) ([]int, error) {
+	var xs []int
+	for _, f := range strings.Split(s, ",") {
+		x, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, x)
+	}
+	return xs, nil
+}
+
+// Sum returns the sum of xs.
+func Sum(xs []int) int {
+	sum := 0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum
+}
+
+// Max returns the larger of a and b.
+func Max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Clear removes all integers from the set, and has no match in the reference package.
+func (s *Set) Clear() {
+	for i := range s.words {
+		s.words[i] = 0
+	}
+}
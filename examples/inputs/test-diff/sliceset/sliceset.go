@@ -0,0 +1,93 @@
+// Package intset is a simple reference implementation of a set of small
+// non-negative integers backed by a sorted slice, which has the same API
+// as the bitmap-based implementation in the parent directory.
+package intset
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Set is a set of non-negative integers.
+type Set struct {
+	max   int
+	elems []int
+}
+
+// New returns a Set with capacity for integers up to max.
+func New(max int) (*Set, error) {
+	if max < 0 || max > 1<<16 {
+		return nil, errors.New("max out of range")
+	}
+	return &Set{max: max/64*64 + 63}, nil
+}
+
+// Add adds x to the set, and reports whether it was added.
+func (s *Set) Add(x int) bool {
+	if x < 0 || x > s.max || s.Has(x) {
+		return false
+	}
+	i := sort.SearchInts(s.elems, x)
+	s.elems = append(s.elems, 0)
+	copy(s.elems[i+1:], s.elems[i:])
+	s.elems[i] = x
+	return true
+}
+
+// Has reports whether x is in the set.
+func (s *Set) Has(x int) bool {
+	i := sort.SearchInts(s.elems, x)
+	return i < len(s.elems) && s.elems[i] == x
+}
+
+// Len returns the number of integers in the set.
+func (s *Set) Len() int {
+	return len(s.elems)
+}
+
+// AddAll adds the integers in xs to the set.
+func (s *Set) AddAll(xs ...int) {
+	for _, x := range xs {
+		s.Add(x)
+	}
+}
+
+// Elems returns the integers in the set in increasing order.
+func (s *Set) Elems() []int {
+	if len(s.elems) == 0 {
+		return nil
+	}
+	return append([]int(nil), s.elems...)
+}
+
+// Parse parses a comma-separated list of integers.
+func Parse(s string) ([]int, error) {
+	var xs []int
+	for _, f := range strings.Split(s, ",") {
+		x, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, x)
+	}
+	return xs, nil
+}
+
+// Sum returns the sum of xs.
+func Sum(xs []int) int {
+	sum := 0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum
+}
+
+// Max returns the larger of a and b, and has a different signature in the target package.
+func Max(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,118 @@
+// Package generics is an example for testing generic functions and methods on generic types.
+package generics
+
+import "sync"
+
+// Number is a constraint with a union, so its types should be used for instantiation.
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+// Sum should be instantiated with the types from the Number union.
+func Sum[T Number](nums []T) T {
+	var total T
+	for _, n := range nums {
+		total += n
+	}
+	return total
+}
+
+// Index should be instantiated with the default type arguments that satisfy comparable.
+func Index[T comparable](items []T, target T) int {
+	for i := range items {
+		if items[i] == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// Reverse should be instantiated with each of the default type arguments.
+func Reverse[T any](s []T) []T {
+	r := make([]T, len(s))
+	for i := range s {
+		r[len(s)-1-i] = s[i]
+	}
+	return r
+}
+
+// Pair has two type parameters, and should result in one wrapper per combination.
+func Pair[K comparable, V Number](k K, v V) map[K]V {
+	return map[K]V{k: v}
+}
+
+// Map should be skipped because of the func parameter.
+func Map[T, U any](s []T, f func(T) U) []U {
+	var r []U
+	for _, v := range s {
+		r = append(r, f(v))
+	}
+	return r
+}
+
+// Set is a generic type.
+type Set[T comparable] struct {
+	m map[T]struct{}
+}
+
+// NewSet is a generic constructor, and should be instantiated to match the receiver of the Set methods.
+func NewSet[T comparable]() *Set[T] {
+	return &Set[T]{m: make(map[T]struct{})}
+}
+
+// Add is a method on a generic type, and should be instantiated via the receiver.
+func (s *Set[T]) Add(v T) {
+	if s.m == nil {
+		s.m = make(map[T]struct{})
+	}
+	s.m[v] = struct{}{}
+}
+
+// Has is a method on a generic type.
+func (s *Set[T]) Has(v T) bool {
+	_, ok := s.m[v]
+	return ok
+}
+
+// Cache is a generic type with two type parameters that is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	mu    sync.Mutex
+	items map[K]V
+	size  int
+}
+
+// NewCache is a generic constructor with a parameter.
+func NewCache[K comparable, V any](size int) *Cache[K, V] {
+	if size < 1 {
+		size = 1
+	}
+	return &Cache[K, V]{items: make(map[K]V), size: size}
+}
+
+// Put stores a value, evicting an arbitrary item if the cache is full.
+func (c *Cache[K, V]) Put(k K, v V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[k]; !ok && len(c.items) >= c.size {
+		for old := range c.items {
+			delete(c.items, old)
+			break
+		}
+	}
+	c.items[k] = v
+}
+
+// Get returns a value if present.
+func (c *Cache[K, V]) Get(k K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.items[k]
+	return v, ok
+}
+
+// Len returns the count of items.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
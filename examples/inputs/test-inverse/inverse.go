@@ -0,0 +1,80 @@
+// Package inverse has pairs of package-level functions where one undoes the other,
+// such as Quote and Unquote, which fzgen checks with roundtrip wrappers.
+package inverse
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Point is a point on a grid.
+type Point struct {
+	X, Y int32
+}
+
+// Encode hex encodes src into dst, similar to encoding/ascii85.Encode.
+func Encode(dst, src []byte) int {
+	return hex.Encode(dst, src)
+}
+
+// MaxEncodedLen returns the maximum length of an encoding of n source bytes.
+func MaxEncodedLen(n int) int {
+	return 2 * n
+}
+
+// Decode hex decodes src into dst, similar to encoding/ascii85.Decode.
+func Decode(dst, src []byte, flush bool) (ndst, nsrc int, err error) {
+	if !flush {
+		src = src[:len(src)&^1]
+	}
+	ndst, err = hex.Decode(dst, src)
+	return ndst, 2 * ndst, err
+}
+
+func Quote(s string) string {
+	return strconv.Quote(s)
+}
+
+func Unquote(s string) (string, error) {
+	return strconv.Unquote(s)
+}
+
+func FormatPoint(p Point) string {
+	return strconv.Itoa(int(p.X)) + "," + strconv.Itoa(int(p.Y))
+}
+
+func ParsePoint(s string) (Point, error) {
+	x, y, ok := strings.Cut(s, ",")
+	if !ok {
+		return Point{}, errors.New("missing comma")
+	}
+	px, err := strconv.ParseInt(x, 10, 32)
+	if err != nil {
+		return Point{}, err
+	}
+	py, err := strconv.ParseInt(y, 10, 32)
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{int32(px), int32(py)}, nil
+}
+
+func MarshalPoint(p Point) ([]byte, error) {
+	return []byte(FormatPoint(p)), nil
+}
+
+func UnmarshalPoint(b []byte, p *Point) error {
+	parsed, err := ParsePoint(string(b))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// Escape has no Unescape, so there is no roundtrip wrapper for it.
+func Escape(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
@@ -0,0 +1,13 @@
+package seeds_test
+
+import (
+	"fmt"
+
+	"github.com/thepudds/fzgen/examples/inputs/test-seeds"
+)
+
+func ExampleParse() {
+	v, err := seeds.Parse("v0.9.1")
+	fmt.Println(v, err)
+	// Output: {0 9 1} <nil>
+}
@@ -0,0 +1,83 @@
+// Package seeds is an example for testing harvesting seed corpus values from existing tests.
+package seeds
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed version string.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// Parse is called with constant arguments in the tests and example.
+func Parse(s string) (Version, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) != 3 {
+		return Version{}, errors.New("invalid version")
+	}
+	var v Version
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return Version{}, err
+		}
+		switch i {
+		case 0:
+			v.Major = n
+		case 1:
+			v.Minor = n
+		case 2:
+			v.Patch = n
+		}
+	}
+	return v, nil
+}
+
+// Clamp takes a mix of types natively supported by cmd/go.
+func Clamp(n int64, lo int64, hi int64, wrap bool) int64 {
+	switch {
+	case n < lo && wrap:
+		return hi
+	case n < lo:
+		return lo
+	case n > hi && wrap:
+		return lo
+	case n > hi:
+		return hi
+	}
+	return n
+}
+
+// Match takes a map, which requires fz.Fill, so the seeds are encoded.
+func Match(pattern string, b []byte, n uint16, counts map[string]int) bool {
+	return strings.Contains(string(b), pattern) && len(counts) >= int(n)
+}
+
+// Buffer accumulates bytes.
+type Buffer struct {
+	buf   []byte
+	limit int
+}
+
+// NewBuffer is called with a constant argument in the tests.
+func NewBuffer(limit int) *Buffer {
+	return &Buffer{limit: limit}
+}
+
+// Write is called with constant arguments in the tests.
+func (b *Buffer) Write(p []byte) int {
+	n := len(p)
+	if len(b.buf)+n > b.limit {
+		n = b.limit - len(b.buf)
+	}
+	b.buf = append(b.buf, p[:n]...)
+	return n
+}
+
+// Grow is not called with constant arguments in the tests.
+func (b *Buffer) Grow(n int) {
+	b.limit += n
+}
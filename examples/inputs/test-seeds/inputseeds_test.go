@@ -0,0 +1,40 @@
+package seeds
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"v1.2.3", "bad"} {
+		// Not a constant argument, so not harvested.
+		Parse(s)
+	}
+	if _, err := Parse("1.20.3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse("1.2"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestClamp(t *testing.T) {
+	if got := Clamp(-5, 0, 10, false); got != 0 {
+		t.Errorf("Clamp() = %d, want 0", got)
+	}
+	if got := Clamp(11, 0, 10, true); got != 0 {
+		t.Errorf("Clamp() = %d, want 0", got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	if !Match("lo", []byte("hello"), 0, nil) {
+		t.Error("Match() = false, want true")
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	b := NewBuffer(8)
+	if n := b.Write([]byte("hello")); n != 5 {
+		t.Errorf("Write() = %d, want 5", n)
+	}
+	grow := 4
+	b.Grow(grow)
+}
@@ -0,0 +1,311 @@
+package fuzzer
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/sanity-io/litter"
+	"github.com/thepudds/fzgen/fuzzer/internal/plan"
+)
+
+// ChainDiff is like Chain, but executes each planned Step against two implementations of
+// the same API, such as a fast path and a reference implementation, or a new version
+// and an old one. refSteps must be the same length as steps, and each Func in refSteps
+// must have the same signature as the corresponding Func in steps.
+//
+// The same plan is used for both, and each call receives identical arguments, with
+// the reference calls receiving deep copies of any newly filled or reused input args
+// so that neither implementation can observe mutations made by the other.
+// A return value that is used as an argument to a later call comes from the same
+// implementation as that later call. After each call, the results are compared as
+// described for DiffResults, and ChainDiff panics with both results, the history of
+// results from both for the steps executed so far, and a repro if they differ.
+//
+// ChainDiff always executes the steps sequentially, and ignores ChainParallel.
+// A given input data []byte results in the same plan and arguments for ChainDiff as for Chain.
+func (fz *Fuzzer) ChainDiff(steps, refSteps []Step, options ...ChainOpt) {
+	if len(steps) != len(refSteps) {
+		panic(fmt.Sprintf("fzgen: ChainDiff: %d steps but %d reference steps", len(steps), len(refSteps)))
+	}
+	for i := range steps {
+		t, refT := reflect.TypeOf(steps[i].Func), reflect.TypeOf(refSteps[i].Func)
+		if t != refT {
+			panic(fmt.Sprintf("fzgen: ChainDiff: step %s has type %v but reference step %s has type %v",
+				steps[i].Name, t, refSteps[i].Name, refT))
+		}
+	}
+
+	fz.applyChainOpts(options)
+	pl := fz.drawPlan(steps)
+	fz.chainDiff(steps, refSteps, pl, "reference")
+}
+
+// modelSteps returns the Steps from model that match each of steps by Name, with a zero
+// Step for any without a match. It panics if a match has a Func of a different type.
+func modelSteps(steps, model []Step) []Step {
+	byName := make(map[string]Step, len(model))
+	for _, m := range model {
+		byName[m.Name] = m
+	}
+	refSteps := make([]Step, len(steps))
+	for i, step := range steps {
+		m, ok := byName[step.Name]
+		if !ok {
+			continue
+		}
+		t, modelT := reflect.TypeOf(step.Func), reflect.TypeOf(m.Func)
+		if t != modelT {
+			panic(fmt.Sprintf("fzgen: ChainModel: step %s has type %v but model step has type %v", step.Name, t, modelT))
+		}
+		refSteps[i] = m
+	}
+	return refSteps
+}
+
+// chainDiff executes the plan against both steps and refSteps, comparing the results of each call.
+// A refSteps entry with a nil Func is executed only against steps. ref names the reference
+// implementation in any panic message, such as "reference" or "model".
+func (fz *Fuzzer) chainDiff(steps, refSteps []Step, pl plan.Plan, ref string) {
+	// Draw the same bytes as chain so that a given input is interpreted the same way
+	// by Chain and ChainDiff, though we always execute sequentially here.
+	fz.calcParallelControl()
+	execCalls := fz.prepareCalls(steps, pl)
+	var parallelPlan byte
+	fz.fillControl(&parallelPlan)
+
+	if debugPrintRepro {
+		fmt.Printf("PLANNED STEPS: (sequential: %v)\n\n", true)
+		emitBasicRepro(os.Stdout, execCalls, true, 0, 0)
+	}
+	chainStats.recordChain(steps, true)
+	defer chainStats.maybeWrite()
+
+	// Copy all of the input args for the reference calls before executing anything,
+	// tracking the copies so that a reused input is also reused by the reference calls.
+	copies := make(map[*reflect.Value]reflect.Value)
+	for _, ec := range execCalls {
+		for _, arg := range ec.args {
+			if _, ok := copies[arg.val]; !ok && !arg.useReturnVal {
+				copies[arg.val] = deepCopy(*arg.val)
+			}
+		}
+	}
+
+	var history []string
+	refRets := make([][]reflect.Value, len(execCalls))
+	for i, ec := range execCalls {
+		if fz.skipCall(ec) {
+			// Neither implementation executes this call, including because its precondition is false.
+			ec.releaseOutputs()
+			chainStats.recordSkip(ec)
+			history = append(history, fmt.Sprintf("\t%d: %s skipped", ec.index+1, ec.name))
+			continue
+		}
+
+		var refArgs []reflect.Value
+		for _, arg := range ec.args {
+			if arg.useReturnVal {
+				refArgs = append(refArgs, nonNil(refRets[arg.slot.returnValCall][arg.slot.returnValArg]))
+			} else {
+				refArgs = append(refArgs, nonNil(copies[arg.val]))
+			}
+		}
+
+		ret := fz.invokeStep(ec)
+		fz.checkInvariant(execCalls, i, true, 0, 0)
+		stop := fz.checkStepError(execCalls, i, ret, true, 0, 0)
+		refStep := refSteps[ec.step]
+		if refStep.Func == nil {
+			// Only the target implements this step, so later reference calls get a copy of its results.
+			refRets[i] = make([]reflect.Value, len(ret))
+			for j := range ret {
+				refRets[i][j] = deepCopy(ret[j])
+			}
+			history = append(history, historyEntry(ec, interfaces(ret), nil))
+			if stop {
+				return
+			}
+			continue
+		}
+		refRets[i] = mustFunc(refStep.Func).Call(refArgs)
+
+		got, want := interfaces(ret), interfaces(refRets[i])
+		history = append(history, historyEntry(ec, got, want))
+		if diff := DiffResults(got, want); diff != "" {
+			var repro strings.Builder
+			emitBasicRepro(&repro, execCalls[:i+1], true, 0, 0)
+			panic(fmt.Sprintf("fzgen: results of step %s differ from %s step %s at call %d (-got +%s):\n%s\n"+
+				"got: %s\n%s: %s\nstep history (got / %s):\n%s\nsteps:\n%s",
+				ec.name, ref, refStep.Name, i+1, ref, diff, compactDump.Sdump(got), ref, compactDump.Sdump(want),
+				ref, strings.Join(history, "\n"), repro.String()))
+		}
+		if stop {
+			return
+		}
+	}
+}
+
+// compactDump formats values in panic messages. We use our own litter.Options
+// rather than modifying the global litter.Config, which the target might also use.
+var compactDump = litter.Options{Compact: true}
+
+// historyEntry describes a call and its results for the step history in a panic message, such as:
+//     3: Fuzz_Cache_Get(__fzCall1Retval1) -> ["a", true] / ["b", true]
+// refResults is nil if the step was only executed against the target.
+func historyEntry(ec execCall, results, refResults []interface{}) string {
+	var args []string
+	for _, arg := range ec.args {
+		if arg.useReturnVal {
+			// one-based temp variable names to match the repro.
+			args = append(args, fmt.Sprintf("__fzCall%dRetval%d", arg.slot.returnValCall+1, arg.slot.returnValArg+1))
+		} else {
+			args = append(args, compactDump.Sdump(arg.val.Interface()))
+		}
+	}
+	entry := fmt.Sprintf("\t%d: %s(%s) -> %s", ec.index+1, ec.name, strings.Join(args, ", "), dumpResults(results))
+	if refResults == nil {
+		return entry + " (not compared)"
+	}
+	return entry + " / " + dumpResults(refResults)
+}
+
+// dumpResults formats results as a compact list, such as ["a", true].
+func dumpResults(results []interface{}) string {
+	var dumps []string
+	for _, r := range results {
+		dumps = append(dumps, compactDump.Sdump(r))
+	}
+	return "[" + strings.Join(dumps, ", ") + "]"
+}
+
+// CheckDiff panics if the results of a call to name differ from the results of the same call
+// to a reference implementation. The args are included in the panic message as a repro.
+// The results are compared as described for DiffResults.
+func CheckDiff(name string, args, results, refResults []interface{}) {
+	diff := DiffResults(results, refResults)
+	if diff == "" {
+		return
+	}
+	var repro []string
+	for _, arg := range args {
+		repro = append(repro, compactDump.Sdump(arg))
+	}
+	panic(fmt.Sprintf("fzgen: results of %s differ from reference (-got +reference):\n%s\ngot: %s\nreference: %s\nrepro: %s(%s)",
+		name, diff, compactDump.Sdump(results), compactDump.Sdump(refResults), name, strings.Join(repro, ", ")))
+}
+
+// DiffResults returns a report of the differences between the results of calls to two
+// implementations of the same API, or the empty string if the results are equal.
+// The results are compared via cmp.Diff, including any unexported fields.
+// Two error results are considered equal if both are nil or both are non-nil,
+// because two implementations rarely return identical errors.
+func DiffResults(results, refResults []interface{}) string {
+	return cmp.Diff(withoutErrors(results), withoutErrors(refResults), diffOpts...)
+}
+
+var diffOpts = []cmp.Option{
+	cmp.Exporter(func(reflect.Type) bool { return true }),
+	// Handle errors nested in other results, such as in a struct.
+	cmp.Comparer(func(x, y error) bool { return (x == nil) == (y == nil) }),
+}
+
+// DiffRoundtrip returns a report of the differences between an original value and the
+// result of encoding and decoding it, or the empty string if they are equal.
+// Unlike reflect.DeepEqual, it considers nil and empty slices and maps equal, as well as
+// two NaN floats, because a correct encoder and decoder often do not preserve those distinctions.
+func DiffRoundtrip(original, decoded interface{}) string {
+	return cmp.Diff(original, decoded, roundtripOpts...)
+}
+
+var roundtripOpts = []cmp.Option{
+	cmp.Exporter(func(reflect.Type) bool { return true }),
+	cmpopts.EquateEmpty(),
+	cmpopts.EquateNaNs(),
+}
+
+// nonNilError replaces non-nil error results when comparing results,
+// which allows two different error types to compare as equal.
+type nonNilError struct{}
+
+// withoutErrors returns a copy of results with any non-nil errors replaced by nonNilError.
+func withoutErrors(results []interface{}) []interface{} {
+	out := make([]interface{}, len(results))
+	for i, r := range results {
+		if _, ok := r.(error); ok {
+			r = nonNilError{}
+		}
+		out[i] = r
+	}
+	return out
+}
+
+// interfaces returns the values in vals as a []interface{}.
+func interfaces(vals []reflect.Value) []interface{} {
+	result := make([]interface{}, len(vals))
+	for i, v := range vals {
+		result[i] = v.Interface()
+	}
+	return result
+}
+
+// deepCopy returns a copy of v that does not share any memory reachable via maps, pointers,
+// slices, or interfaces with v, other than through unexported fields of structs, which are copied
+// shallowly. It is sufficient for values created by Fill, which only fills exported fields.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(deepCopy(iter.Key()), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	default:
+		return v
+	}
+}
@@ -0,0 +1,260 @@
+package fuzzer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thepudds/fzgen/fuzzer/internal/plan"
+)
+
+func TestFuzzerChainDiff(t *testing.T) {
+	// Setup a sequential plan by hand that is effectively:
+	//     ret := step1(0)  // 0 because with an empty data []byte, any filled in value will be the type's zero value.
+	//     step2(ret)       // reuse return value from step1 based on how we set up plan below.
+	returnReusePlan := plan.Plan{
+		Calls: []plan.Call{
+			{
+				StepIndex: 0,
+				ArgSource: []plan.ArgSource{{SourceType: 2}}, // new arg
+			},
+			{
+				StepIndex: 1,
+				ArgSource: []plan.ArgSource{{SourceType: 1}}, // return value from step1
+			},
+		},
+	}
+	// A plan that calls step1 twice with the same input arg.
+	inputReusePlan := plan.Plan{
+		Calls: []plan.Call{
+			{
+				StepIndex: 0,
+				ArgSource: []plan.ArgSource{{SourceType: 2}}, // new arg
+			},
+			{
+				StepIndex: 0,
+				ArgSource: []plan.ArgSource{{SourceType: 0}}, // reuse input arg
+			},
+		},
+	}
+
+	add := func(a int) int { return a + 42 }
+	itoa := func(a int) string { return fmt.Sprint(a) }
+	tests := []struct {
+		name     string
+		steps    []Step
+		refSteps []Step
+		pl       plan.Plan
+		wantDiff bool
+	}{
+		{
+			name:     "same results",
+			steps:    []Step{{Name: "add", Func: add}, {Name: "itoa", Func: itoa}},
+			refSteps: []Step{{Name: "add", Func: add}, {Name: "itoa", Func: itoa}},
+			pl:       returnReusePlan,
+		},
+		{
+			name:  "different results from reused return value",
+			steps: []Step{{Name: "add", Func: add}, {Name: "itoa", Func: itoa}},
+			refSteps: []Step{{Name: "add", Func: add}, {Name: "itoa", Func: func(a int) string {
+				if a == 42 {
+					return "forty-two"
+				}
+				return fmt.Sprint(a)
+			}}},
+			pl:       returnReusePlan,
+			wantDiff: true,
+		},
+		{
+			name: "errors of different types",
+			steps: []Step{{Name: "add", Func: add}, {Name: "check", Func: func(a int) (int, error) {
+				return a, errors.New("bad")
+			}}},
+			refSteps: []Step{{Name: "add", Func: add}, {Name: "check", Func: func(a int) (int, error) {
+				return a, fs.ErrInvalid
+			}}},
+			pl: returnReusePlan,
+		},
+		{
+			name: "error and nil error",
+			steps: []Step{{Name: "add", Func: add}, {Name: "check", Func: func(a int) (int, error) {
+				return a, errors.New("bad")
+			}}},
+			refSteps: []Step{{Name: "add", Func: add}, {Name: "check", Func: func(a int) (int, error) {
+				return a, nil
+			}}},
+			pl:       returnReusePlan,
+			wantDiff: true,
+		},
+		{
+			// The reference gets its own copy of the reused input, so it is
+			// not affected by the first implementation's increments.
+			name:     "mutated input",
+			steps:    []Step{{Name: "incr", Func: func(p *int) int { *p++; return *p }}},
+			refSteps: []Step{{Name: "incr", Func: func(p *int) int { *p++; return *p }}},
+			pl:       inputReusePlan,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err := recover()
+				s, _ := err.(string)
+				gotDiff := strings.Contains(s, "differ from reference")
+				if err != nil && !gotDiff {
+					panic(err)
+				}
+				if gotDiff != tt.wantDiff {
+					t.Errorf("chainDiff() reported difference = %v, want %v. panic: %v", gotDiff, tt.wantDiff, err)
+				}
+			}()
+
+			fz := NewFuzzer([]byte{})
+			fz.chainDiff(tt.steps, tt.refSteps, tt.pl, "reference")
+		})
+	}
+}
+
+func TestFuzzerChainModel(t *testing.T) {
+	// A set backed by a slice with a bug in removal, and a map-backed model.
+	var elems []int
+	add := func(x int) {
+		for _, e := range elems {
+			if e == x {
+				return
+			}
+		}
+		elems = append(elems, x)
+	}
+	remove := func(x int) {
+		for i, e := range elems {
+			if e == x {
+				elems = elems[i+1:] // bug: drops everything before x
+				return
+			}
+		}
+	}
+	length := func() int { return len(elems) }
+
+	model := make(map[int]bool)
+	mSteps := []Step{
+		{Name: "add", Func: func(x int) { model[x] = true }},
+		{Name: "remove", Func: func(x int) { delete(model, x) }},
+		{Name: "len", Func: func() int { return len(model) }},
+	}
+
+	// A plan that is effectively:
+	//     add(0)
+	//     add(0)   // reuse input arg, though any filled in value is zero.
+	//     debug()  // not in the model.
+	//     remove(0)
+	//     len()
+	pl := plan.Plan{
+		Calls: []plan.Call{
+			{StepIndex: 0, ArgSource: []plan.ArgSource{{SourceType: 2}}},
+			{StepIndex: 0, ArgSource: []plan.ArgSource{{SourceType: 2}}},
+			{StepIndex: 3},
+			{StepIndex: 1, ArgSource: []plan.ArgSource{{SourceType: 2}}},
+			{StepIndex: 2},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		elems     []int
+		wantPanic string
+	}{
+		{
+			name: "matches model",
+		},
+		{
+			// remove(0) also drops 5, so len() differs at the fifth call.
+			name:      "diverges from model",
+			elems:     []int{5},
+			wantPanic: "results of step len differ from model step len at call 5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elems = tt.elems
+			model = make(map[int]bool)
+			for _, e := range tt.elems {
+				model[e] = true
+			}
+			defer func() {
+				err := recover()
+				s, _ := err.(string)
+				if tt.wantPanic == "" && err != nil {
+					panic(err)
+				}
+				if !strings.Contains(s, tt.wantPanic) {
+					t.Fatalf("chainDiff() panic = %v, want %q", err, tt.wantPanic)
+				}
+				if tt.wantPanic != "" && !strings.Contains(s, "3: debug() -> [] (not compared)") {
+					t.Errorf("chainDiff() panic missing step history: %v", s)
+				}
+			}()
+
+			steps := []Step{{Name: "add", Func: add}, {Name: "remove", Func: remove}, {Name: "len", Func: length}, {Name: "debug", Func: func() {}}}
+			fz := NewFuzzer([]byte{})
+			fz.chainDiff(steps, modelSteps(steps, mSteps), pl, "model")
+		})
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	type inner struct{ S []int }
+	type outer struct {
+		P *inner
+		M map[string][]byte
+		A [2][]int
+	}
+	orig := outer{
+		P: &inner{S: []int{1, 2}},
+		M: map[string][]byte{"a": {1}},
+		A: [2][]int{{3}, {4}},
+	}
+	c := deepCopy(reflect.ValueOf(orig)).Interface().(outer)
+	if DiffResults([]interface{}{orig}, []interface{}{c}) != "" {
+		t.Fatalf("deepCopy() = %+v, want %+v", c, orig)
+	}
+
+	c.P.S[0] = 100
+	c.M["a"][0] = 100
+	c.A[1][0] = 100
+	if orig.P.S[0] != 1 || orig.M["a"][0] != 1 || orig.A[1][0] != 4 {
+		t.Errorf("deepCopy() result shares memory with original: %+v", orig)
+	}
+}
+
+func TestDiffRoundtrip(t *testing.T) {
+	type point struct {
+		X    float64
+		Tags []string
+		m    map[string]int
+	}
+	tests := []struct {
+		name     string
+		original interface{}
+		decoded  interface{}
+		wantDiff bool
+	}{
+		{"nil and empty slice", point{Tags: nil}, point{Tags: []string{}}, false},
+		{"nil and empty map", point{m: nil}, point{m: map[string]int{}}, false},
+		{"NaN", point{X: math.NaN()}, point{X: math.NaN()}, false},
+		{"different values", point{Tags: []string{"a"}}, point{Tags: []string{"b"}}, true},
+		{"different unexported values", point{m: map[string]int{"a": 1}}, point{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffRoundtrip(tt.original, tt.decoded)
+			if (diff != "") != tt.wantDiff {
+				t.Errorf("DiffRoundtrip() = %q, want difference %v", diff, tt.wantDiff)
+			}
+		})
+	}
+}
@@ -13,12 +13,15 @@ package fuzzer
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sanity-io/litter"
 	"github.com/thepudds/fzgen/fuzzer/internal/plan"
@@ -32,9 +35,77 @@ var SupportedInterfaces = randparam.SupportedInterfaces
 // Func can take any number of arguments and return any number of values.
 // The Name string conventionally should be an acceptable func identifier.
 // See Chain for more details on usage.
+//
+// Weight and Pre are optional. Weight is the relative likelihood of Chain choosing the Step,
+// where zero means the default of 4. For example, a Weight of 1 can be used for a Step like Close
+// that otherwise cuts short a chain, and a Weight of 16 can favor a rarely reached Step.
+// If Pre is set, Chain calls it just before each call of the Step and skips the call if
+// Pre returns false, such as for a Pop on an empty stack. Pre can be called concurrently
+// with other Steps when Steps run in parallel.
 type Step struct {
-	Name string
-	Func interface{}
+	Name   string
+	Func   interface{}
+	Weight int
+	Pre    func() bool
+}
+
+// defaultWeight is the Weight of a Step that does not set its Weight.
+const defaultWeight = 4
+
+// chooseStep returns the index into steps selected by call.
+// If no Step sets a Weight, this is call.StepIndex modulo the number of steps. Otherwise, each Step
+// is selected by a share of the possible values of call.StepIndex in proportion to its Weight.
+// If the sum of the Weights is above 256, call.StepIndexHigh is also used (see wideStepIndex)
+// so that every Step remains reachable, up to a sum of 65536.
+func chooseStep(steps []Step, call plan.Call) int {
+	total, weighted := totalWeight(steps)
+	if !weighted {
+		return int(call.StepIndex) % len(steps)
+	}
+	var pos int
+	if total > 256 {
+		pos = (int(call.StepIndexHigh)<<8 | int(call.StepIndex)) * total / 65536
+	} else {
+		pos = int(call.StepIndex) * total / 256
+	}
+	for i, step := range steps {
+		pos -= stepWeight(step)
+		if pos < 0 {
+			return i
+		}
+	}
+	panic("bug computing step index")
+}
+
+// wideStepIndex reports whether a plan.Call for steps needs a StepIndexHigh byte
+// in addition to its StepIndex, which is the case if any Step sets a Weight and the sum
+// of the Weights is above 256.
+func wideStepIndex(steps []Step) bool {
+	total, weighted := totalWeight(steps)
+	return weighted && total > 256
+}
+
+// totalWeight returns the sum of the Weights of steps, including defaults,
+// and whether any Step sets a Weight.
+func totalWeight(steps []Step) (total int, weighted bool) {
+	for _, step := range steps {
+		if step.Weight < 0 {
+			panic(fmt.Sprintf("fzgen: step %s has negative Weight %d", step.Name, step.Weight))
+		}
+		if step.Weight != 0 {
+			weighted = true
+		}
+		total += stepWeight(step)
+	}
+	return total, weighted
+}
+
+// stepWeight returns the Weight of step, including the default.
+func stepWeight(step Step) int {
+	if step.Weight == 0 {
+		return defaultWeight
+	}
+	return step.Weight
 }
 
 // Fuzzer is a utility object that can fill in many types
@@ -55,7 +126,7 @@ type FuzzerOpt func(*Fuzzer) error
 
 // NewFuzzer returns a Fuzzer, which relies on the input data []byte
 // to control its subsequent operations.
-// In the future, NewFuzzer may take options, though currently does not.
+// The only current option is WithDictionary.
 func NewFuzzer(data []byte, options ...FuzzerOpt) (fz *Fuzzer) {
 	fill := randparam.NewFuzzer(data)
 	state := &execState{
@@ -64,11 +135,44 @@ func NewFuzzer(data []byte, options ...FuzzerOpt) (fz *Fuzzer) {
 		// TODO: not needed?
 		// reusableOutputs: make(map[reflect.Type][]reflect.Value),
 	}
-	return &Fuzzer{
+	fz = &Fuzzer{
 		data:            data,
 		randparamFuzzer: fill,
 		execState:       state,
 	}
+	for _, opt := range options {
+		if opt == nil {
+			continue
+		}
+		err := opt(fz)
+		if err != nil {
+			// TODO: currently we have no errors. panic is probably the right way to communicate from inside a fuzz func.
+			panic(err)
+		}
+	}
+	return fz
+}
+
+// Dictionary contains values that Fill can choose from, such as string literals
+// and constants from the code under test. Fuzzing engines often have a hard time
+// producing the exact values that code compares against, such as a magic string
+// or a version number, and a dictionary helps reach those comparisons.
+// fzgen automatically emits a Dictionary with values from the target package.
+type Dictionary struct {
+	Strings []string // used when filling strings and []byte
+	Ints    []int64  // used when filling integers of any size
+}
+
+// WithDictionary sets a Dictionary for Fill to use, including when Chain fills
+// the arguments for Steps. Under the control of the input data []byte,
+// Fill sometimes chooses a value from the dictionary rather than drawing new bytes.
+// Using a Dictionary changes how the input data []byte is interpreted, so a corpus
+// built without a Dictionary will not reproduce the same values with one.
+func WithDictionary(d Dictionary) FuzzerOpt {
+	return func(fz *Fuzzer) error {
+		fz.randparamFuzzer.SetDictionary(d.Strings, d.Ints)
+		return nil
+	}
 }
 
 // Fill fills in most simple types, maps, slices, arrays, and recursively fills any public members of x.
@@ -101,6 +205,37 @@ func (fz *Fuzzer) Fill(x ...interface{}) {
 	}
 }
 
+// Choose returns a value in the range [0, n) that is determined by the input data,
+// which allows a fuzzing function to select among alternatives, such as choosing
+// which constructor creates the target of a Chain. As with Fill, the choice is
+// controlled by the fuzzing engine and is minimized like any other input byte.
+// Choose returns 0 without consuming any data if n <= 1. Unlike Fill,
+// Choose does not use any Dictionary set via WithDictionary.
+func (fz *Fuzzer) Choose(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	if n <= 256 {
+		var b uint8
+		fz.fillControl(&b)
+		return int(b) % n
+	}
+	u := fz.randparamFuzzer.ControlUint64()
+	return int(u % uint64(n))
+}
+
+// fillControl fills each of bs with a byte from the input data without using any Dictionary,
+// so that the bytes that control a Chain, such as whether to run in parallel, are interpreted
+// the same way with or without a Dictionary. A missing byte is 0, just as with Fill.
+func (fz *Fuzzer) fillControl(bs ...*byte) {
+	for _, b := range bs {
+		*b = fz.randparamFuzzer.ControlUint8()
+	}
+	if debugPrintPlan {
+		fmt.Printf("fzgen: filled %d control bytes. %d bytes remaining.\n", len(bs), fz.randparamFuzzer.Remaining())
+	}
+}
+
 type execState struct {
 	// reusableInputs is a map from type to list of all new args of that type from all steps,
 	// ordered by the sequence of calls defined the Plan and the order within the args of a
@@ -139,6 +274,11 @@ type execState struct {
 	// filled in (e.g., after the invocation of call1).
 	// TODO: not needed?
 	// reusableOutputs map[reflect.Type][]reflect.Value
+
+	// stoppedBy is set via atomic operations to the one-based index of the first call
+	// that returns an error when ChainStopOnError is set, after which any remaining calls are skipped.
+	// It is zero if the chain has not stopped.
+	stoppedBy int32
 }
 
 // execCall represents a function call we intend to make, based on which
@@ -148,13 +288,16 @@ type execCall struct {
 
 	name        string
 	index       int           // zero-based index of this call. currently only used for emitting variable name for repro.
+	step        int           // index into the user's Step list.
 	fv          reflect.Value // func we will call.
+	pre         func() bool   // optional precondition for this call.
 	args        []argument    // arguments for this call, some of which might initially be placeholder invalid reflect.Value.
 	outputSlots []*outputSlot // pointers to the output slots for this call's return values.
 }
 
 type argument struct {
 	useReturnVal bool           // indicates if this argument will come from another call's return value.
+	reused       bool           // indicates if this argument reuses an input to another call.
 	typ          reflect.Type   // type of input argument.
 	val          *reflect.Value // argument value to use.
 	slot         *outputSlot    // slot of the return value.
@@ -175,14 +318,37 @@ type outputSlot struct {
 	returnValCall int
 	// zero-indexed arg from that call that the return value will come from.
 	returnValArg int
+	// Ensures we store val and close ch only once, including when a call executes multiple times in a loop.
+	once sync.Once
+}
+
+// broadcast stores v as the return value and closes ch to broadcast that it is ready to be read,
+// unless an earlier execution of the call already did. An invalid v releases anyone waiting on a skipped call.
+func (slot *outputSlot) broadcast(v reflect.Value) {
+	slot.once.Do(func() {
+		slot.val = v
+		close(slot.ch)
+	})
 }
 
 type ChainOpt func(*Fuzzer) error
 
 type chainOpts struct {
-	parallel bool
+	parallel  bool
+	model     []Step
+	invariant func() error
+
+	stopOnError bool
+	failOnError map[string]bool // Step names for which an error is a failure. Empty but non-nil means all Steps.
+
+	maxCalls      int   // maximum calls in a plan. 0 means defaultMaxCalls.
+	loopCounts    []int // loop counts for parallel calls, most favored first. nil means the default distribution.
+	maxGoroutines int   // maximum calls to run in parallel. 0 means no limit beyond the number of calls.
 }
 
+// defaultMaxCalls is the maximum number of calls in a plan if ChainMaxCalls is not set.
+const defaultMaxCalls = 10
+
 // ChainParallel indicates the Fuzzer is allowed to run the
 // defined set of Steps in parallel. The Fuzzer can choose to run
 // all selected Steps in parallel, though most often prefers
@@ -204,17 +370,162 @@ func ChainParallel(fz *Fuzzer) error {
 	return nil
 }
 
+// ChainModel returns a ChainOpt that checks the target of a Chain against a reference model,
+// such as a simple map-backed implementation of a cache, which turns a Chain into a stateful
+// property test. Each Step in model is matched by Name with a Step passed to Chain, and
+// must have a Func of the same type.
+//
+// Each planned Step is executed against both the target and the model with identical arguments,
+// and the results are compared after each Step as described for DiffResults. On the first
+// difference, Chain panics with the results from both along with the full history of the
+// Steps executed so far, including the arguments and results of each.
+// A Step without a match in model is executed only against the target, which can be useful for
+// Steps that do not affect the observable state, or which a model cannot reasonably implement.
+//
+// The Steps are always executed sequentially when a model is supplied, and ChainParallel is ignored.
+// A given input data []byte results in the same plan and arguments with or without a model.
+func ChainModel(model []Step) ChainOpt {
+	return func(fz *Fuzzer) error {
+		fz.chainOpts.model = model
+		return nil
+	}
+}
+
+// ChainInvariant returns a ChainOpt that calls check after each Step, such as a func that calls
+// a Validate() error method on the target of the Chain. If check returns a non-nil error or panics,
+// Chain panics with the name of the Step that was just executed and its position in the plan,
+// along with a repro of the Steps executed so far. This catches corruption at the Step that
+// caused it, rather than at some later, confusing crash.
+//
+// When Steps run in parallel, check is called once after all of the parallel Steps complete.
+func ChainInvariant(check func() error) ChainOpt {
+	return func(fz *Fuzzer) error {
+		fz.chainOpts.invariant = check
+		return nil
+	}
+}
+
+// ChainContinueOnError indicates Chain should continue executing the planned Steps
+// after a Step returns a non-nil error as its last return value. This is the default.
+func ChainContinueOnError(fz *Fuzzer) error {
+	fz.chainOpts.stopOnError = false
+	return nil
+}
+
+// ChainStopOnError indicates Chain should stop executing the planned Steps after a Step returns
+// a non-nil error as its last return value, which is useful when an error leaves the target
+// in a state that should not be used further. Any Steps already running in parallel
+// complete, and any Steps waiting on a return value from a skipped Step are also skipped.
+// With FZDEBUG=repro=1, Chain also prints the Steps that executed and where the sequence stopped.
+func ChainStopOnError(fz *Fuzzer) error {
+	fz.chainOpts.stopOnError = true
+	return nil
+}
+
+// ChainFailOnError returns a ChainOpt that treats a non-nil error returned as the last return value
+// of the Steps with the given names as a failure, which causes Chain to panic with the error
+// along with a repro of the Steps executed so far. If no names are given, an error from any
+// Step is a failure. An error from other Steps is handled as set by ChainStopOnError or ChainContinueOnError.
+func ChainFailOnError(names ...string) ChainOpt {
+	return func(fz *Fuzzer) error {
+		fz.chainOpts.failOnError = make(map[string]bool)
+		for _, name := range names {
+			fz.chainOpts.failOnError[name] = true
+		}
+		return nil
+	}
+}
+
+// ChainMaxCalls returns a ChainOpt that sets the maximum number of Steps called in a Chain, which defaults to 10.
+// Chain still favors 3 to 5 calls, but can choose any number of calls up to n, which can help reach
+// problems that only occur after dozens of operations. A loop for parallel Steps (see ChainLoopCounts)
+// can result in more total calls. The same input data []byte results in the same calls for a given n,
+// but setting n above 64 changes how some inputs are interpreted.
+func ChainMaxCalls(n int) ChainOpt {
+	return func(fz *Fuzzer) error {
+		if n < 1 {
+			return fmt.Errorf("fzgen: ChainMaxCalls: n must be at least 1, got %d", n)
+		}
+		fz.chainOpts.maxCalls = n
+		return nil
+	}
+}
+
+// ChainLoopCounts returns a ChainOpt that sets how many times each Step is called in a loop when
+// Steps run in parallel, which can help reveal a race. The first count is favored and is chosen for
+// half of the possible inputs, and the remaining counts evenly split the other half.
+// The default is equivalent to ChainLoopCounts(1, 4, 16, 64, 256), though it favors the smaller
+// counts more heavily. A return value cannot be reused by the same Step across loop iterations,
+// but it can be reused by a later Step, which waits for the first iteration to complete.
+func ChainLoopCounts(counts ...int) ChainOpt {
+	return func(fz *Fuzzer) error {
+		if len(counts) == 0 || len(counts) > 128 {
+			return fmt.Errorf("fzgen: ChainLoopCounts: must have 1 to 128 counts, got %d", len(counts))
+		}
+		for _, c := range counts {
+			if c < 1 {
+				return fmt.Errorf("fzgen: ChainLoopCounts: counts must be at least 1, got %d", c)
+			}
+		}
+		fz.chainOpts.loopCounts = counts
+		return nil
+	}
+}
+
+// ChainMaxFillSize returns a ChainOpt that sets the bound on the number of elements in
+// slices and maps other than []byte that Chain fills in as arguments for Steps, which
+// are filled with fewer than n elements. The default is 10. It also applies to any
+// subsequent calls to Fill with the same Fuzzer.
+func ChainMaxFillSize(n int) ChainOpt {
+	return func(fz *Fuzzer) error {
+		if n < 1 {
+			return fmt.Errorf("fzgen: ChainMaxFillSize: n must be at least 1, got %d", n)
+		}
+		fz.randparamFuzzer.SetMaxElements(n)
+		return nil
+	}
+}
+
+// ChainParallelism returns a ChainOpt that sets the maximum number of Steps
+// that run in parallel when ChainParallel is set. It has no effect without ChainParallel.
+// A maxGoroutines of 1 means the Steps always run sequentially.
+func ChainParallelism(maxGoroutines int) ChainOpt {
+	return func(fz *Fuzzer) error {
+		if maxGoroutines < 1 {
+			return fmt.Errorf("fzgen: ChainParallelism: maxGoroutines must be at least 1, got %d", maxGoroutines)
+		}
+		fz.chainOpts.maxGoroutines = maxGoroutines
+		return nil
+	}
+}
+
 // Chain invokes a set of Steps, looking for problematic sequences and input arguments.
 // The Fuzzer chooses which Steps to calls and how often to call them,
 // then creates any needed arguments, and calls the Steps in a sequence selected by the fuzzer.
-// The only current option is ChainOptParallel.
-// If the last return value of a Step is of type error and a non-nil value is returned,
-// this indicates a sequence of Steps should stop execution,
-// The only current option is ChainOptParallel.
+// The current options are ChainParallel, ChainModel, ChainInvariant, and the options for
+// handling a Step that returns a non-nil error as its last return value, which are
+// ChainContinueOnError (the default), ChainStopOnError, and ChainFailOnError,
+// along with ChainMaxCalls, ChainLoopCounts, ChainParallelism, and ChainMaxFillSize
+// to adjust how many Steps are called, how they run in parallel, and the size of their arguments.
+//
+// With FZDEBUG=stats=<path>, Chain counts the calls, skipped calls, panics, error returns,
+// reused arguments, and time spent for each Step across all chains in the process, and
+// periodically writes a report to <path>.<pid>, or to stderr with FZDEBUG=stats=stderr.
+// A Step with few or no calls might need a larger Weight, or a change to the harness.
 func (fz *Fuzzer) Chain(steps []Step, options ...ChainOpt) {
-	// Start by filling in our plan, which will let us know the sequence of steps along
-	// with sources for input args (which might be re-using input args,
-	// or using return values, or new values from fz.Fill).
+	fz.applyChainOpts(options)
+	pl := fz.drawPlan(steps)
+	if fz.chainOpts.model != nil {
+		fz.chainDiff(steps, modelSteps(steps, fz.chainOpts.model), pl, "model")
+		return
+	}
+	fz.chain(steps, pl)
+}
+
+// drawPlan fills in our plan, which will let us know the sequence of steps along
+// with sources for input args (which might be re-using input args,
+// or using return values, or new values from fz.Fill).
+func (fz *Fuzzer) drawPlan(steps []Step) plan.Plan {
 	pl := plan.Plan{}
 	before := fz.randparamFuzzer.Remaining()
 	switch debugPlanVersion {
@@ -225,7 +536,11 @@ func (fz *Fuzzer) Chain(steps []Step, options ...ChainOpt) {
 		buf := bytes.NewReader(data)
 
 		// Convert those bytes into a Plan.
-		pl = unmarshalPlan(buf, steps)
+		maxCalls := fz.chainOpts.maxCalls
+		if maxCalls == 0 {
+			maxCalls = defaultMaxCalls
+		}
+		pl = unmarshalPlan(buf, steps, maxCalls)
 
 		// Drain from randparamFuzzer any bytes we used building the Plan.
 		used := len(data) - buf.Len()
@@ -239,7 +554,11 @@ func (fz *Fuzzer) Chain(steps []Step, options ...ChainOpt) {
 		fmt.Printf("fzgen: filled Plan using %d bytes. %d bytes remaining.\n",
 			before-fz.randparamFuzzer.Remaining(), fz.randparamFuzzer.Remaining())
 	}
+	return pl
+}
 
+// applyChainOpts applies the options passed to Chain.
+func (fz *Fuzzer) applyChainOpts(options []ChainOpt) {
 	// Using functional options.
 	// (Side note: Rob Pike's blog introducing functional options is a great read:
 	//     https://commandcenter.blogspot.com/2014/01/self-referential-functions-and-design.html)
@@ -255,8 +574,6 @@ func (fz *Fuzzer) Chain(steps []Step, options ...ChainOpt) {
 			panic(err)
 		}
 	}
-
-	fz.chain(steps, pl)
 }
 
 func (fz *Fuzzer) chain(steps []Step, pl plan.Plan) {
@@ -265,37 +582,9 @@ func (fz *Fuzzer) chain(steps []Step, pl plan.Plan) {
 	// First, determine if we will spin and loop for any parallel calls.
 	allowSpin, loopCount := fz.calcParallelControl()
 
-	// Second, create our list of execCalls based on the list of plan.Calls.
-	// We do not yet fully populate the arguments for an execCall,
-	// which we will do on a subsequent pass.
-	execCalls := make([]execCall, len(pl.Calls))
-	for i := range pl.Calls {
-		// Based on the plan, compute index into the user's Step list.
-		s := int(pl.Calls[i].StepIndex) % len(steps)
-
-		ec := execCall{
-			planCall: pl.Calls[i],
-			name:     steps[s].Name,
-			index:    i,
-			fv:       mustFunc(steps[s].Func),
-			args:     []argument{}, // empty to start, we will fill in below.
-		}
-
-		execCalls[i] = ec
-	}
-
-	// Third, create arguments as needed for each execCall,
-	// or record that we will obtain an argument from the
-	// return value of an earlier execCall.
-	for i := range execCalls {
-		allowReturnValReuse := loopCount == 1
-		// Build arguments for this call, and also get its reflect.Value function.
-		// This can update the execCall to track outputSlots.
-		args := fz.prepareStep(&execCalls[i], allowReturnValReuse, fz.Fill)
-
-		// Track what we need to execute this call later.
-		execCalls[i].args = args
-	}
+	// Second, create our list of execCalls based on the list of plan.Calls,
+	// along with their arguments.
+	execCalls := fz.prepareCalls(steps, pl)
 
 	// TODO: consider reintroducing shuffle of plan or execCalls, though might have less benefit after interweaving filling args
 	// with filling the plan, which then gives the fuzzing engine a better chance of reordering.
@@ -319,8 +608,9 @@ func (fz *Fuzzer) chain(steps []Step, pl plan.Plan) {
 	// Also, we try to take advantage of ASCII '0' minimization behavior of cmd/go
 	// to mean serial, and then as cmd/go minimization steps to ASCII '1', '2', '3', ...,
 	// we interpret those to mean pair parallel, stepping from the end.
-	fz.Fill(&parallelPlan)
-	if parallelAllowed && len(execCalls) > 1 {
+	fz.fillControl(&parallelPlan)
+	maxGoroutines := fz.chainOpts.maxGoroutines
+	if parallelAllowed && len(execCalls) > 1 && maxGoroutines != 1 {
 		switch {
 		case parallelPlan == '0' || parallelPlan < 32:
 			sequential = true
@@ -331,12 +621,18 @@ func (fz *Fuzzer) chain(steps []Step, pl plan.Plan) {
 			startParallelIndex, stopParallelIndex = calcParallelN(parallelPlan, len(execCalls))
 			sequential = false
 		}
+		if maxGoroutines > 0 && stopParallelIndex-startParallelIndex+1 > maxGoroutines {
+			// Keep the parallel calls nearest the end, similar to calcParallelN.
+			startParallelIndex = stopParallelIndex - maxGoroutines + 1
+		}
 	}
 
 	if sequential {
 		// This only matters for debug output, but might as well make it clear.
 		allowSpin, loopCount = false, 1
 	}
+	chainStats.recordChain(steps, sequential)
+	defer chainStats.maybeWrite()
 
 	if debugPrintPlan {
 		fmt.Printf("fzgen: parallelPlan byte: %v startParallelIndex: %d stopParallelIndex: %d sequential: %v\n",
@@ -348,13 +644,17 @@ func (fz *Fuzzer) chain(steps []Step, pl plan.Plan) {
 		} else {
 			fmt.Printf("PLANNED STEPS: (sequential: %v, loop count: %d, spin: %v)\n\n", sequential, loopCount, allowSpin)
 		}
-		emitBasicRepro(execCalls, sequential, startParallelIndex, stopParallelIndex)
+		emitBasicRepro(os.Stdout, execCalls, sequential, startParallelIndex, stopParallelIndex)
 	}
 
 	// Invoke our chained calls!
 	if sequential {
-		for _, ec := range execCalls {
-			fz.callStep(ec)
+		for i, ec := range execCalls {
+			ret := fz.callStep(ec)
+			fz.checkInvariant(execCalls, i, true, 0, 0)
+			if fz.checkStepError(execCalls, i, ret, true, 0, 0) {
+				break
+			}
 		}
 	} else {
 		var wg sync.WaitGroup
@@ -371,7 +671,10 @@ func (fz *Fuzzer) chain(steps []Step, pl plan.Plan) {
 				go func(i int) {
 					defer wg.Done()
 					for j := 0; j < loopCount; j++ {
-						fz.callStep(execCalls[i])
+						ret := fz.callStep(execCalls[i])
+						if fz.checkStepError(execCalls, i, ret, false, startParallelIndex, stopParallelIndex) {
+							break
+						}
 					}
 				}(i)
 
@@ -379,15 +682,59 @@ func (fz *Fuzzer) chain(steps []Step, pl plan.Plan) {
 					// Return to sequential execution, waiting on our in-flight goroutines
 					// we just started above.
 					wg.Wait()
+					fz.checkInvariant(execCalls, i, false, startParallelIndex, stopParallelIndex)
 				}
 			} else {
 				// Everything outside of start/StopParallelIndex runs sequentially.
-				fz.callStep(execCalls[i])
+				// If the chain has stopped, callStep skips the call.
+				ret := fz.callStep(execCalls[i])
+				fz.checkInvariant(execCalls, i, false, startParallelIndex, stopParallelIndex)
+				fz.checkStepError(execCalls, i, ret, false, startParallelIndex, stopParallelIndex)
 			}
 		}
 	}
 }
 
+// prepareCalls creates our list of execCalls based on the list of plan.Calls in pl,
+// and then creates arguments as needed for each execCall, or records that
+// we will obtain an argument from the return value of an earlier execCall.
+func (fz *Fuzzer) prepareCalls(steps []Step, pl plan.Plan) []execCall {
+	// Return values can only come from calls in this plan.
+	if fz.execState != nil {
+		fz.execState.outputSlots = make(map[reflect.Type][]*outputSlot)
+	}
+
+	// We do not yet fully populate the arguments for an execCall,
+	// which we will do on a subsequent pass.
+	execCalls := make([]execCall, len(pl.Calls))
+	for i := range pl.Calls {
+		// Based on the plan, compute index into the user's Step list.
+		s := chooseStep(steps, pl.Calls[i])
+
+		ec := execCall{
+			planCall: pl.Calls[i],
+			name:     steps[s].Name,
+			index:    i,
+			step:     s,
+			fv:       mustFunc(steps[s].Func),
+			pre:      steps[s].Pre,
+			args:     []argument{}, // empty to start, we will fill in below.
+		}
+
+		execCalls[i] = ec
+	}
+
+	for i := range execCalls {
+		// Build arguments for this call, and also get its reflect.Value function.
+		// This can update the execCall to track outputSlots.
+		args := fz.prepareStep(&execCalls[i], fz.Fill)
+
+		// Track what we need to execute this call later.
+		execCalls[i].args = args
+	}
+	return execCalls
+}
+
 // calcParallelControl draws and interprets bytes to control our spinning and looping.
 func (fz *Fuzzer) calcParallelControl() (allowSpin bool, loopCount int) {
 	// TODO: probably move drawing the bytes to marshal.go.
@@ -395,14 +742,22 @@ func (fz *Fuzzer) calcParallelControl() (allowSpin bool, loopCount int) {
 	// (Previously, we had a couple different flavors of randomized goroutine ordering
 	// via a seed byte, but that is disabled).
 	var spinPlan, loopPlan, orderPlan byte
-	fz.Fill(&spinPlan, &loopPlan, &orderPlan)
+	fz.fillControl(&spinPlan, &loopPlan, &orderPlan)
 
 	// We prefer to spin (mostly to aid with reproducibility), including if '0' or 0x0 appear during minimization.
 	// (And yes, '0' is less than 192, but being explicit here as reminder when the numbers get juggled).
 	allowSpin = spinPlan == '0' || loopPlan < 192
 
 	// We prefer to not to loop much (mostly for perf & mem usage), including if '0' or 0x0 appear during minimization.
+	counts := fz.chainOpts.loopCounts
 	switch {
+	case counts != nil:
+		// Favor the first count, and evenly split the rest of the values among any other counts.
+		if loopPlan == '0' || loopPlan < 128 || len(counts) == 1 {
+			loopCount = counts[0]
+		} else {
+			loopCount = counts[1+int(loopPlan-128)*(len(counts)-1)/128]
+		}
 	case loopPlan == '0' || loopPlan < 128:
 		loopCount = 1
 	case loopPlan < 224:
@@ -473,6 +828,8 @@ func calcParallelN(parallelPlan byte, execCallLen int) (startParallelIndex, stop
 	return startParallelIndex, stopParallelIndex
 }
 
+// callStep waits for any return values ec needs from earlier calls, and then calls
+// the Step unless the call is skipped (see skipCall), in which case it returns nil.
 func (fz *Fuzzer) callStep(ec execCall) []reflect.Value {
 	// TODO: don't need all these args eventually
 
@@ -483,47 +840,43 @@ func (fz *Fuzzer) callStep(ec execCall) []reflect.Value {
 		}
 	}
 
+	if fz.skipCall(ec) {
+		// We release anyone waiting on our return values, who will then also skip.
+		ec.releaseOutputs()
+		chainStats.recordSkip(ec)
+		return nil
+	}
+	return fz.invokeStep(ec)
+}
+
+// invokeStep calls the Step for ec with its already prepared args, and
+// broadcasts any return values needed by subsequent calls.
+func (fz *Fuzzer) invokeStep(ec execCall) []reflect.Value {
 	// Prepare the reflect.Value arg list we will use to call the func.
 	// This contains the input values we previously created.
 	reflectArgs := []reflect.Value{}
 	for i := range ec.args {
-		v := *ec.args[i].val
-
-		// For map, pointer, or slice, we disallow nil values to
-		// be passed in as args by creating a new object here if nil. Note that we are not setting up
-		// for example a map completely -- just making sure it is not nil.
-		// In older versions, this arg nil check was emitted code someone could choose to delete.
-		// We could return and skip this call (closer to older emitted logic), but
-		// if we do that, we need to handle the outputslot broadcast channels for this call in case someone
-		// is waiting or will be waiting on a return value from this call.
-		// TODO: this is likely useful for Ptr, but less sure how useful this is for the other types here.
-		// TODO: test this better. inputs/race/race.go tests this for Ptr, but this is slightly annoying to test
-		// because fz.Fill avoids this. This occurs for example when the plan decides to reuse a call return
-		// value and that function under test returns a nil. In that case, fz.Fill is not the one creating the value.
-		// TODO: if we keep this, consider showing equivalent logic in the emitted repro logic, or maybe only when it matters.
-		// TODO: consider skipping this instead, and emit the nil check logic in the repro.
-		// TODO: make this configurable, including because people no longer have option of deleting the emitted nil checks.
-		switch v.Kind() {
-		case reflect.Ptr:
-			if v.IsNil() {
-				v = reflect.New(v.Type().Elem())
-			}
-		case reflect.Slice:
-			if v.IsNil() {
-				v = reflect.MakeSlice(v.Type(), 0, 0)
-			}
-		case reflect.Map:
-			if v.IsNil() {
-				v = reflect.MakeMapWithSize(v.Type(), 0)
-			}
-		case reflect.Interface:
-			// TODO: consider checking Interface too. Or better to keep passing the code under test a nil?
-		}
+		v := nonNil(*ec.args[i].val)
 		reflectArgs = append(reflectArgs, v)
 	}
 
-	// Call the user's func.
-	ret := ec.fv.Call(reflectArgs)
+	// Call the user's func, recording statistics if enabled.
+	var ret []reflect.Value
+	if chainStats == nil {
+		ret = ec.fv.Call(reflectArgs)
+	} else {
+		completed := false
+		defer func() {
+			if !completed {
+				// We are panicking, which we let continue without recovering.
+				chainStats.recordPanic(ec)
+			}
+		}()
+		start := time.Now()
+		ret = ec.fv.Call(reflectArgs)
+		chainStats.recordCall(ec, ret, time.Since(start))
+		completed = true
+	}
 
 	if len(ret) != ec.fv.Type().NumOut() {
 		panic("fzgen: mismatch on return value count")
@@ -532,6 +885,12 @@ func (fz *Fuzzer) callStep(ec execCall) []reflect.Value {
 		panic(fmt.Sprintf("fzgen: for execCall %v, mismatch on return value count vs. execCall.outputSlots count: %+v, %+v", ec.name, ret, ec.outputSlots))
 	}
 
+	// If we are stopping on errors, mark the chain as stopped before broadcasting our
+	// return values so that any subsequent call waiting on them is skipped.
+	if fz.execState != nil && fz.chainOpts.stopOnError && stepError(ret) != nil {
+		atomic.CompareAndSwapInt32(&fz.execState.stoppedBy, 0, int32(ec.index+1))
+	}
+
 	// Check to see if any of these return results are needed by an subsequent call.
 	if fz.execState != nil {
 		for i := 0; i < ec.fv.Type().NumOut(); i++ {
@@ -544,12 +903,9 @@ func (fz *Fuzzer) callStep(ec execCall) []reflect.Value {
 					panic("fzgen: mismatch on return value types")
 				}
 
-				// store this return value in the right outputSlot for later use by a subsequent call.
-				slot := ec.outputSlots[i]
-				slot.val = outV
-
-				// Broadcast that the slot.val is ready to be read.
-				close(slot.ch)
+				// store this return value in the right outputSlot for later use by a subsequent call,
+				// and broadcast that the slot.val is ready to be read.
+				ec.outputSlots[i].broadcast(outV)
 			}
 		}
 	}
@@ -559,7 +915,148 @@ func (fz *Fuzzer) callStep(ec execCall) []reflect.Value {
 	return ret
 }
 
-func (fz *Fuzzer) prepareStep(ec *execCall, allowReturnValReuse bool, fillFunc func(...interface{})) []argument {
+// skipCall reports whether to skip call ec, which is the case if an earlier Step returned an error
+// that stopped the chain, if an argument is a return value from a skipped call, or if the Step's
+// precondition is false.
+func (fz *Fuzzer) skipCall(ec execCall) bool {
+	if fz.stopped() {
+		return true
+	}
+	for _, arg := range ec.args {
+		if arg.useReturnVal && !arg.slot.val.IsValid() {
+			return true
+		}
+	}
+	return ec.pre != nil && !ec.pre()
+}
+
+// releaseOutputs broadcasts an invalid value for any return values of a skipped call
+// that are needed by subsequent calls.
+func (ec execCall) releaseOutputs() {
+	for _, slot := range ec.outputSlots {
+		if slot.needed {
+			slot.broadcast(reflect.Value{})
+		}
+	}
+}
+
+// stopped reports whether an earlier Step returned an error that stopped the chain.
+func (fz *Fuzzer) stopped() bool {
+	return fz.execState != nil && atomic.LoadInt32(&fz.execState.stoppedBy) != 0
+}
+
+// checkStepError applies the error handling set via ChainOpts to any error returned as the last of
+// the results ret from call i, and reports whether the chain should stop. It panics if the error is a
+// failure. The remaining parameters describe how the calls were executed, as for emitBasicRepro.
+func (fz *Fuzzer) checkStepError(calls []execCall, i int, ret []reflect.Value, sequential bool, startParallelIndex, stopParallelIndex int) bool {
+	err := stepError(ret)
+	if err == nil {
+		return false
+	}
+	ec := calls[i]
+	fail := fz.chainOpts.failOnError
+	if fail != nil && (len(fail) == 0 || fail[ec.name]) {
+		var repro strings.Builder
+		emitBasicRepro(&repro, calls[:i+1], sequential, startParallelIndex, stopParallelIndex)
+		panic(fmt.Sprintf("fzgen: step %s returned an error at call %d of %d: %v\nsteps:\n%s",
+			ec.name, i+1, len(calls), err, repro.String()))
+	}
+	if !fz.stopped() {
+		return false
+	}
+	if debugPrintRepro && atomic.LoadInt32(&fz.execState.stoppedBy) == int32(i+1) {
+		fmt.Printf("STOPPED: step %s returned an error at call %d of %d: %v\n\nEXECUTED STEPS:\n\n", ec.name, i+1, len(calls), err)
+		emitBasicRepro(os.Stdout, calls[:i+1], sequential, startParallelIndex, stopParallelIndex)
+		fmt.Printf("\t// %s returned an error, which stopped the chain.\n\n", ec.name)
+	}
+	return true
+}
+
+// stepError returns the last of the results of a Step if it is a non-nil error, or nil otherwise.
+func stepError(ret []reflect.Value) error {
+	if len(ret) == 0 {
+		return nil
+	}
+	last := ret[len(ret)-1]
+	if last.Type() != errorType || last.IsNil() {
+		return nil
+	}
+	return last.Interface().(error)
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// checkInvariant calls any invariant func supplied via ChainInvariant after call i, and panics
+// if it fails. The remaining parameters describe how the calls were executed, as for emitBasicRepro.
+func (fz *Fuzzer) checkInvariant(calls []execCall, i int, sequential bool, startParallelIndex, stopParallelIndex int) {
+	check := fz.chainOpts.invariant
+	if check == nil {
+		return
+	}
+	fail := func(failure interface{}) {
+		where := fmt.Sprintf("step %s at call %d of %d", calls[i].name, i+1, len(calls))
+		if !sequential && i == stopParallelIndex && startParallelIndex < stopParallelIndex {
+			where = fmt.Sprintf("parallel calls %d-%d of %d, ending with step %s",
+				startParallelIndex+1, stopParallelIndex+1, len(calls), calls[i].name)
+		}
+		var repro strings.Builder
+		emitBasicRepro(&repro, calls[:i+1], sequential, startParallelIndex, stopParallelIndex)
+		panic(fmt.Sprintf("fzgen: invariant failed after %s: %v\nsteps:\n%s", where, failure, repro.String()))
+	}
+	var err error
+	func() {
+		// Panic from within the deferred func so that the traceback includes where check panicked.
+		defer func() {
+			if r := recover(); r != nil {
+				fail(r)
+			}
+		}()
+		err = check()
+	}()
+	if err != nil {
+		fail(err)
+	}
+}
+
+// nonNil returns v, or if v is a nil map, pointer, or slice, a new non-nil value of the same type.
+func nonNil(v reflect.Value) reflect.Value {
+	// For map, pointer, or slice, we disallow nil values to
+	// be passed in as args by creating a new object here if nil. Note that we are not setting up
+	// for example a map completely -- just making sure it is not nil.
+	// In older versions, this arg nil check was emitted code someone could choose to delete.
+	// We could return and skip this call (closer to older emitted logic), but
+	// if we do that, we need to handle the outputslot broadcast channels for this call in case someone
+	// is waiting or will be waiting on a return value from this call.
+	// TODO: this is likely useful for Ptr, but less sure how useful this is for the other types here.
+	// TODO: test this better. inputs/race/race.go tests this for Ptr, but this is slightly annoying to test
+	// because fz.Fill avoids this. This occurs for example when the plan decides to reuse a call return
+	// value and that function under test returns a nil. In that case, fz.Fill is not the one creating the value.
+	// TODO: if we keep this, consider showing equivalent logic in the emitted repro logic, or maybe only when it matters.
+	// TODO: consider skipping this instead, and emit the nil check logic in the repro.
+	// TODO: make this configurable, including because people no longer have option of deleting the emitted nil checks.
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v = reflect.New(v.Type().Elem())
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			v = reflect.MakeSlice(v.Type(), 0, 0)
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			v = reflect.MakeMapWithSize(v.Type(), 0)
+		}
+	case reflect.Interface:
+		// TODO: consider checking Interface too. Or better to keep passing the code under test a nil?
+	}
+	return v
+}
+
+// prepareStep creates or finds the arguments for ec. The plan can reuse an input arg or a return value
+// of the same type, using ArgIndex to select among the candidates. Only return values from earlier calls
+// are candidates, which means a call never waits on a return value that will not be ready in time.
+func (fz *Fuzzer) prepareStep(ec *execCall, fillFunc func(...interface{})) []argument {
 	// TODO: additional sanity checking on types?
 	fv := ec.fv
 	ft := fv.Type()
@@ -576,44 +1073,42 @@ func (fz *Fuzzer) prepareStep(ec *execCall, allowReturnValReuse bool, fillFunc f
 
 		// Check if our plan indicates we should try to reuse an input or output.
 		if fz.execState != nil && len(ec.planCall.ArgSource) > i {
+			argIndex := int(ec.planCall.ArgSource[i].ArgIndex)
 			switch ec.planCall.ArgSource[i].SourceType % 3 {
 			case 0:
 				// Reuse an argument, if one can be found.
-				inputs, ok := fz.execState.reusableInputs[inT]
-				if ok && len(inputs) > 0 {
-					// TODO: take index from plan eventually; for simple tests, fine to take first
-					inV := inputs[0]
-					// We want the Elem() for use below in Call, because
-					// inV represents a pointer to the type we want (e.g. from reflect.New),
-					// so we do the indirect via inV.Elem() to get our original type.
-					inElem := inV.Elem()
+				inputs := fz.execState.reusableInputs[inT]
+				if len(inputs) > 0 {
+					// The stored value is already the type we want for use below in Call.
 					arg = argument{
 						useReturnVal: false,
-						typ:          inV.Type(),
-						val:          &inElem,
+						reused:       true,
+						typ:          inT,
+						val:          inputs[argIndex%len(inputs)],
 					}
 					createNew = false
 				}
 			case 1:
-				if allowReturnValReuse {
-					// Mark that we will use a return value from an earlier step, if one can be found.
-					outputSlots, ok := fz.execState.outputSlots[inT]
-					if ok && len(outputSlots) > 0 {
-						// We found a return value.
-						// TODO: BUG: Note that it could be from any step, including one which happens
-						// after us.
-						// TODO: take index from plan eventually; for simple tests, fine to take first
-						outputSlot := outputSlots[0]
-						outputSlot.needed = true
-						arg = argument{
-							useReturnVal: true,
-							typ:          inT,
-							val:          &outputSlot.val,
-							slot:         outputSlot,
-						}
-						createNew = false
+				// Mark that we will use a return value from an earlier call, if one can be found.
+				// We add a call's outputSlots after preparing its args, so these are all from earlier calls.
+				var outputSlots []*outputSlot
+				for _, slot := range fz.execState.outputSlots[inT] {
+					if slot.returnValCall < ec.index {
+						outputSlots = append(outputSlots, slot)
 					}
 				}
+				if len(outputSlots) > 0 {
+					// We found a return value.
+					outputSlot := outputSlots[argIndex%len(outputSlots)]
+					outputSlot.needed = true
+					arg = argument{
+						useReturnVal: true,
+						typ:          inT,
+						val:          &outputSlot.val,
+						slot:         outputSlot,
+					}
+					createNew = false
+				}
 			}
 		}
 
@@ -631,7 +1126,7 @@ func (fz *Fuzzer) prepareStep(ec *execCall, allowReturnValReuse bool, fillFunc f
 			inElem := inV.Elem()
 			arg = argument{
 				useReturnVal: false,
-				typ:          inV.Type(),
+				typ:          inT,
 				val:          &inElem,
 			}
 
@@ -701,6 +1196,7 @@ var (
 	debugPrintRepro  bool
 	debugPrintPlan   bool
 	debugPlanVersion int = 2
+	debugStatsPath   string
 )
 
 func emitPlan(pl plan.Plan) {
@@ -732,7 +1228,7 @@ func emitPlan(pl plan.Plan) {
 //             [4]uint8{0,0,0,0},
 //             __fzCall2Retval1,
 //     )
-func emitBasicRepro(calls []execCall, sequential bool, startParallelIndex int, stopParallelIndex int) {
+func emitBasicRepro(w io.Writer, calls []execCall, sequential bool, startParallelIndex int, stopParallelIndex int) {
 	litter.Config.Compact = true
 	// TODO: Probably use litter.Options object
 	// TODO: litter.Config.HomePackage = "<local pkg>"
@@ -745,28 +1241,28 @@ func emitBasicRepro(calls []execCall, sequential bool, startParallelIndex int, s
 
 		// TODO: consider emitting spin?
 		// if i > startParallelIndex && i <= stopParallelIndex {
-		// 	fmt.Print("\n\tspin()\n")
+		// 	fmt.Fprint(w, "\n\tspin()\n")
 		// }
 
 		if parallelCall && i == startParallelIndex {
 			if i != 0 {
-				fmt.Println()
+				fmt.Fprintln(w)
 			}
-			fmt.Print("\tvar wg sync.WaitGroup\n")
-			fmt.Printf("\twg.Add(%d)\n\n", stopParallelIndex-startParallelIndex+1)
-			fmt.Print("\t// Execute next steps in parallel.\n")
+			fmt.Fprint(w, "\tvar wg sync.WaitGroup\n")
+			fmt.Fprintf(w, "\twg.Add(%d)\n\n", stopParallelIndex-startParallelIndex+1)
+			fmt.Fprint(w, "\t// Execute next steps in parallel.\n")
 		}
 
 		if parallelCall {
-			fmt.Print("\tgo func() {\n")
-			fmt.Print("\t\tdefer wg.Done()\n")
+			fmt.Fprint(w, "\tgo func() {\n")
+			fmt.Fprint(w, "\t\tdefer wg.Done()\n")
 		}
 
 		// start emititng the actual call invocation.
 		if parallelCall {
-			fmt.Print("\t\t")
+			fmt.Fprint(w, "\t\t")
 		} else {
-			fmt.Print("\t")
+			fmt.Fprint(w, "\t")
 		}
 
 		// check if we are reusing any of return values from this call.
@@ -783,49 +1279,49 @@ func emitBasicRepro(calls []execCall, sequential bool, startParallelIndex int, s
 			//    __fzCall2Retval1, _, _ :=
 			for i, slot := range ec.outputSlots {
 				if i > 0 {
-					fmt.Print(", ")
+					fmt.Fprint(w, ", ")
 				}
 				if !ec.outputSlots[i].needed {
-					fmt.Print("_")
+					fmt.Fprint(w, "_")
 				} else {
 					// one-based temp variable names for friendlier output.
-					fmt.Printf("__fzCall%dRetval%d", slot.returnValCall+1, slot.returnValArg+1)
+					fmt.Fprintf(w, "__fzCall%dRetval%d", slot.returnValCall+1, slot.returnValArg+1)
 				}
 			}
-			fmt.Print(" := ")
+			fmt.Fprint(w, " := ")
 		}
 
 		// emit the args, which might just be literals, or
 		// might include one or more temp variables for a return value.
-		fmt.Printf("%s(\n", ec.name)
+		fmt.Fprintf(w, "%s(\n", ec.name)
 		for _, arg := range ec.args {
 			if parallelCall {
-				fmt.Print("\t")
+				fmt.Fprint(w, "\t")
 			}
 			if !arg.useReturnVal {
-				fmt.Printf("\t\t%s,\n", litter.Sdump(arg.val.Interface()))
+				fmt.Fprintf(w, "\t\t%s,\n", litter.Sdump(arg.val.Interface()))
 			} else {
 				// one-based temp variable names for friendlier output.
-				fmt.Printf("\t\t__fzCall%dRetval%d,\n", arg.slot.returnValCall+1, arg.slot.returnValArg+1)
+				fmt.Fprintf(w, "\t\t__fzCall%dRetval%d,\n", arg.slot.returnValCall+1, arg.slot.returnValArg+1)
 			}
 		}
 
 		// close out the invocation of this call.
 		if parallelCall {
-			fmt.Print("\t\t)\n")
-			fmt.Print("\t}()\n")
+			fmt.Fprint(w, "\t\t)\n")
+			fmt.Fprint(w, "\t}()\n")
 		} else {
-			fmt.Print("\t)\n")
+			fmt.Fprint(w, "\t)\n")
 		}
 
 		if parallelCall && i == stopParallelIndex {
-			fmt.Print("\twg.Wait()\n")
+			fmt.Fprint(w, "\twg.Wait()\n")
 			if i < len(calls)-1 {
-				fmt.Printf("\n\t// Resume sequential execution.\n")
+				fmt.Fprintf(w, "\n\t// Resume sequential execution.\n")
 			}
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 func init() {
@@ -853,6 +1349,13 @@ func fzgenDebugParse() {
 				debugPrintPlan = true
 			}
 		}
+		if strings.HasPrefix(f, "stats=") {
+			debugStatsPath = strings.TrimPrefix(f, "stats=")
+			if debugStatsPath == "" {
+				panic("unexpected stats value in FZDEBUG env var")
+			}
+			chainStats = newStats()
+		}
 		if strings.HasPrefix(f, "planversion=") {
 			debugPlanVersion, err := strconv.Atoi(strings.TrimPrefix(f, "planversion="))
 			if err != nil || debugPlanVersion > 2 {
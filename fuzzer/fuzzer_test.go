@@ -2,12 +2,15 @@ package fuzzer
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/thepudds/fzgen/fuzzer/internal/plan"
@@ -61,8 +64,7 @@ func TestCallStep(t *testing.T) {
 				args:     []argument{}, // empty list to start, will be populated by prepareStep
 			}
 
-			allowReturnValReuse := true
-			args := fz.prepareStep(&ec, allowReturnValReuse, fakeFill)
+			args := fz.prepareStep(&ec, fakeFill)
 			ec.args = args
 
 			ret := fz.callStep(ec)
@@ -205,6 +207,322 @@ func TestFuzzerChain(t *testing.T) {
 	}
 }
 
+func TestFuzzerChainReuseArgPointerAndValue(t *testing.T) {
+	// Steps that take both a type and a pointer to that type should each
+	// only reuse input args of exactly the same type.
+	type point struct{ X, Y int }
+	var count int
+	steps := []Step{
+		{
+			Name: "step 1: input point",
+			Func: func(p point) { count++ },
+		},
+		{
+			Name: "step 2: input *point",
+			Func: func(p *point) {
+				if p == nil {
+					panic("unexpected nil *point")
+				}
+				count++
+			},
+		},
+		{
+			Name: "step 3: input point",
+			Func: func(p point) { count++ },
+		},
+	}
+	var calls []plan.Call
+	for i := range steps {
+		calls = append(calls, plan.Call{
+			StepIndex: uint8(i),
+			ArgSource: []plan.ArgSource{{
+				SourceType: 0, // corresponds to reusing an input arg, if one of the same type is available
+				ArgIndex:   0,
+			}},
+		})
+	}
+
+	fz := NewFuzzer([]byte{})
+	fz.chain(steps, plan.Plan{Calls: calls})
+	if count != len(steps) {
+		t.Errorf("chain() completed %d calls, want %d", count, len(steps))
+	}
+}
+
+func TestFuzzerChainArgIndex(t *testing.T) {
+	// A plan that is effectively:
+	//     ret1 := ret(10)
+	//     ret2 := ret(20)
+	//     ret3 := ret(30)
+	//     use(<input or return value chosen by the last call's ArgSource>)
+	// where the first three calls each take a new arg filled by the fuzzer.
+	newArgs := []plan.ArgSource{{SourceType: 2}}
+	lastCall := func(src plan.ArgSource) plan.Plan {
+		return plan.Plan{
+			Calls: []plan.Call{
+				{StepIndex: 0, ArgSource: newArgs},
+				{StepIndex: 0, ArgSource: newArgs},
+				{StepIndex: 0, ArgSource: newArgs},
+				{StepIndex: 1, ArgSource: []plan.ArgSource{src}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		src  plan.ArgSource
+		want int
+	}{
+		{"reuse first input", plan.ArgSource{SourceType: 0, ArgIndex: 0}, 10},
+		{"reuse second input", plan.ArgSource{SourceType: 0, ArgIndex: 1}, 20},
+		{"reuse input with wraparound", plan.ArgSource{SourceType: 0, ArgIndex: 5}, 30},
+		{"reuse first return value", plan.ArgSource{SourceType: 1, ArgIndex: 0}, 11},
+		{"reuse third return value", plan.ArgSource{SourceType: 1, ArgIndex: 2}, 31},
+		{"reuse return value with wraparound", plan.ArgSource{SourceType: 1, ArgIndex: 4}, 21},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int
+			steps := []Step{
+				{Name: "ret", Func: func(a int) int { return a + 1 }},
+				{Name: "use", Func: func(a int) { got = a }},
+			}
+
+			// Fill the new args for the first three calls with 10, 20, and 30.
+			fz := NewFuzzer([]byte{})
+			next := 0
+			fill := func(args ...interface{}) {
+				next += 10
+				*args[0].(*int) = next
+			}
+			pl := lastCall(tt.src)
+			var calls []execCall
+			for i, c := range pl.Calls {
+				calls = append(calls, execCall{planCall: c, index: i, name: steps[c.StepIndex].Name, fv: mustFunc(steps[c.StepIndex].Func)})
+			}
+			for i := range calls {
+				calls[i].args = fz.prepareStep(&calls[i], fill)
+			}
+			for _, ec := range calls {
+				fz.callStep(ec)
+			}
+			if got != tt.want {
+				t.Errorf("use() called with %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzerChainReturnReuse(t *testing.T) {
+	// A plan that is effectively:
+	//     ret := step1()
+	//     step2(ret)
+	// which we execute in parallel with a loop count above 1, and then again
+	// with a second chain using the same Fuzzer, which must not wait on
+	// return values from the first chain.
+	pl := plan.Plan{
+		Calls: []plan.Call{
+			{StepIndex: 0},
+			{StepIndex: 1, ArgSource: []plan.ArgSource{{SourceType: 1}}},
+		},
+	}
+	var calls, sum int32
+	steps := []Step{
+		{Name: "step1", Func: func() int { atomic.AddInt32(&calls, 1); return 42 }},
+		{Name: "step2", Func: func(a int) {
+			atomic.AddInt32(&calls, 1)
+			atomic.AddInt32(&sum, int32(a))
+		}},
+	}
+
+	// The bytes are the reserved first byte, then the spin, loop count, and order bytes,
+	// where 224 means a loop count of 16, and finally the parallelPlan byte.
+	fz := NewFuzzer([]byte{0, 0, 224, 0, 255})
+	fz.applyChainOpts([]ChainOpt{ChainParallel})
+	fz.chain(steps, pl)
+	if calls != 32 || sum != 16*42 {
+		t.Errorf("chain() completed %d calls with sum of step2 args %d, want 32 calls and sum %d", calls, sum, 16*42)
+	}
+
+	// A second chain with a new step1 that returns a different type, so step2
+	// could only reuse a stale return value from the first chain, and instead
+	// gets a new arg, which is zero because we have used all of our data.
+	calls, sum = 0, 0
+	steps[0].Func = func() string { return "" }
+	fz.chain(steps, pl)
+	if calls != 1 || sum != 0 {
+		t.Errorf("second chain() completed %d calls with sum of step2 args %d, want 1 call and sum 0", calls, sum)
+	}
+}
+
+func TestFuzzerChainInvariant(t *testing.T) {
+	// A counter whose invariant is that it stays below 3, and which
+	// is incremented by each call to the first step.
+	tests := []struct {
+		name      string
+		calls     int
+		check     func(n int) error
+		wantPanic string
+	}{
+		{
+			name:  "holds",
+			calls: 2,
+			check: func(n int) error {
+				if n >= 3 {
+					return fmt.Errorf("n = %d", n)
+				}
+				return nil
+			},
+		},
+		{
+			name:  "error",
+			calls: 4,
+			check: func(n int) error {
+				if n >= 3 {
+					return fmt.Errorf("n = %d", n)
+				}
+				return nil
+			},
+			wantPanic: "fzgen: invariant failed after step incr at call 3 of 4: n = 3",
+		},
+		{
+			name:  "panic",
+			calls: 4,
+			check: func(n int) error {
+				if n >= 3 {
+					panic("too big")
+				}
+				return nil
+			},
+			wantPanic: "fzgen: invariant failed after step incr at call 3 of 4: too big",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n int
+			steps := []Step{{Name: "incr", Func: func() { n++ }}}
+			calls := make([]plan.Call, tt.calls)
+
+			defer func() {
+				err := recover()
+				s, _ := err.(string)
+				if tt.wantPanic == "" && err != nil {
+					panic(err)
+				}
+				if !strings.HasPrefix(s, tt.wantPanic) {
+					t.Errorf("chain() panic = %v, want %q", err, tt.wantPanic)
+				}
+			}()
+
+			fz := NewFuzzer([]byte{})
+			fz.applyChainOpts([]ChainOpt{ChainInvariant(func() error { return tt.check(n) })})
+			fz.chain(steps, plan.Plan{Calls: calls})
+		})
+	}
+}
+
+func TestFuzzerChainErrors(t *testing.T) {
+	// A plan that is effectively:
+	//     ret := step1()
+	//     step2(ret)
+	//     step1()
+	// where step1 returns an error, and step2 reuses the return value from the first call.
+	pl := plan.Plan{
+		Calls: []plan.Call{
+			{StepIndex: 0},
+			{StepIndex: 1, ArgSource: []plan.ArgSource{{SourceType: 1}}},
+			{StepIndex: 0},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		options   []ChainOpt
+		parallel  byte    // parallelPlan byte drawn by chain, where 0 means sequential
+		wantCalls []int32 // calls per step, where -1 means any count
+		wantPanic string
+	}{
+		{
+			name:      "continue by default",
+			wantCalls: []int32{2, 1},
+		},
+		{
+			name:      "continue",
+			options:   []ChainOpt{ChainStopOnError, ChainContinueOnError},
+			wantCalls: []int32{2, 1},
+		},
+		{
+			name:      "stop",
+			options:   []ChainOpt{ChainStopOnError},
+			wantCalls: []int32{1, 0},
+		},
+		{
+			// The second call waits on the return value from the first call, and
+			// must be released and skipped when the first call stops the chain.
+			// The third call might start before the chain stops.
+			name:      "stop parallel",
+			options:   []ChainOpt{ChainParallel, ChainStopOnError},
+			parallel:  255,
+			wantCalls: []int32{-1, 0},
+		},
+		{
+			name:      "fail for step",
+			options:   []ChainOpt{ChainFailOnError("step1")},
+			wantPanic: "fzgen: step step1 returned an error at call 1 of 3: bad",
+		},
+		{
+			name:      "fail for other step",
+			options:   []ChainOpt{ChainFailOnError("step2")},
+			wantCalls: []int32{2, 1},
+		},
+		{
+			name:      "fail for all steps",
+			options:   []ChainOpt{ChainFailOnError()},
+			wantPanic: "fzgen: step step1 returned an error at call 1 of 3: bad",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := make([]int32, 2)
+			steps := []Step{
+				{
+					Name: "step1",
+					Func: func() (int, error) {
+						atomic.AddInt32(&calls[0], 1)
+						return 42, errors.New("bad")
+					},
+				},
+				{
+					Name: "step2",
+					Func: func(int) { atomic.AddInt32(&calls[1], 1) },
+				},
+			}
+
+			defer func() {
+				err := recover()
+				s, _ := err.(string)
+				if tt.wantPanic == "" && err != nil {
+					panic(err)
+				}
+				if !strings.HasPrefix(s, tt.wantPanic) {
+					t.Errorf("chain() panic = %v, want %q", err, tt.wantPanic)
+				}
+			}()
+
+			// The byte to control parallelism is the last byte drawn, after the reserved
+			// first byte and the spin, loop count, and order bytes, which we draw as zero.
+			fz := NewFuzzer([]byte{0, 0, 0, 0, tt.parallel})
+			fz.applyChainOpts(tt.options)
+			fz.chain(steps, pl)
+			for i, want := range tt.wantCalls {
+				if want >= 0 && calls[i] != want {
+					t.Errorf("chain() completed %d calls of %s, want %d", calls[i], steps[i].Name, want)
+				}
+			}
+		})
+	}
+}
+
 // fakeFill is a simple test standin for fzgen/fuzzer.Fuzzer.Fill.
 // must take pointer to value of interest. For example, to fill an int:
 //     var a int
@@ -253,6 +571,40 @@ func TestFuzzerFill(t *testing.T) {
 			t.Errorf("fuzzer.Fill() mismatch (-want +got):\n%s", diff)
 		}
 	})
+
+	t.Run("string and int - dictionary", func(t *testing.T) {
+		input := []byte{0x0, 0xFE, 0x1, 0xF0, 0x0}
+		want := []interface{}{"beta", 42}
+
+		fz := NewFuzzer(input, WithDictionary(Dictionary{Strings: []string{"alpha", "beta"}, Ints: []int64{42}}))
+		var s string
+		var i int
+		fz.Fill(&s, &i)
+		if diff := cmp.Diff(want, []interface{}{s, i}); diff != "" {
+			t.Errorf("fuzzer.Fill() mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestFuzzerChoose(t *testing.T) {
+	// The first byte is reserved, and Choose(1) does not consume any data.
+	input := []byte{0x0, 0x05, 0x07}
+	fz := NewFuzzer(input)
+	got := []int{fz.Choose(3), fz.Choose(1), fz.Choose(4), fz.Choose(2)}
+	want := []int{2, 0, 3, 0}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("fuzzer.Choose() mismatch (-want +got):\n%s", diff)
+	}
+
+	// A dictionary does not change how Choose interprets the input,
+	// even for a byte that would otherwise select a dictionary entry.
+	input = []byte{0x0, 0xFF, 0x01}
+	fz = NewFuzzer(input, WithDictionary(Dictionary{Ints: []int64{1}}))
+	got = []int{fz.Choose(256), fz.Choose(256)}
+	want = []int{255, 1}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("fuzzer.Choose() with dictionary mismatch (-want +got):\n%s", diff)
+	}
 }
 
 func TestCalcParallelPair(t *testing.T) {
@@ -307,3 +659,174 @@ func TestCalcParallelN(t *testing.T) {
 		})
 	}
 }
+
+func TestCalcParallelControl(t *testing.T) {
+	tests := []struct {
+		name          string
+		loopCounts    []int
+		loopPlan      byte
+		wantLoopCount int
+	}{
+		{"default 0", nil, 0, 1},
+		{"default 128", nil, 128, 4},
+		{"default 250", nil, 250, 64},
+		{"default 255", nil, 255, 256},
+		{"custom 0", []int{2, 8, 32}, 0, 2},
+		{"custom '0'", []int{2, 8, 32}, '0', 2},
+		{"custom 128", []int{2, 8, 32}, 128, 8},
+		{"custom 191", []int{2, 8, 32}, 191, 8},
+		{"custom 192", []int{2, 8, 32}, 192, 32},
+		{"custom 255", []int{2, 8, 32}, 255, 32},
+		{"single count", []int{3}, 255, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The bytes are the reserved first byte, then the spin, loop count, and order bytes.
+			// The dictionary must not change how the bytes are interpreted.
+			fz := NewFuzzer([]byte{0, 0, tt.loopPlan, 0}, WithDictionary(Dictionary{Ints: []int64{1}}))
+			if tt.loopCounts != nil {
+				fz.applyChainOpts([]ChainOpt{ChainLoopCounts(tt.loopCounts...)})
+			}
+			_, gotLoopCount := fz.calcParallelControl()
+			if gotLoopCount != tt.wantLoopCount {
+				t.Errorf("calcParallelControl() loopCount = %v, want %v", gotLoopCount, tt.wantLoopCount)
+			}
+		})
+	}
+}
+
+func TestFuzzerChainParallelism(t *testing.T) {
+	// A plan that calls step1 five times, which records how many calls overlap.
+	pl := plan.Plan{Calls: make([]plan.Call, 5)}
+
+	tests := []struct {
+		name          string
+		maxGoroutines int
+		wantMax       int32
+	}{
+		{"sequential", 1, 1},
+		{"two", 2, 2},
+		{"unlimited", 5, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, max int32
+			steps := []Step{{Name: "step1", Func: func() {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&max)
+					if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+						break
+					}
+				}
+				// Give any parallel calls a chance to overlap.
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+			}}}
+
+			// The bytes are the reserved first byte, then the spin, loop count, and order bytes,
+			// and finally the parallelPlan byte, where 227 means all five calls are parallel.
+			fz := NewFuzzer([]byte{0, 0, 0, 0, 227})
+			fz.applyChainOpts([]ChainOpt{ChainParallel, ChainParallelism(tt.maxGoroutines)})
+			fz.chain(steps, pl)
+			if max > tt.wantMax {
+				t.Errorf("chain() ran %d steps in parallel, want at most %d", max, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestChooseStep(t *testing.T) {
+	unweighted := []Step{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	// b has the default Weight of 4, for a total Weight of 8 and 32 values of stepIndex per unit of Weight.
+	weighted := []Step{{Name: "a", Weight: 1}, {Name: "b"}, {Name: "c", Weight: 3}}
+	// 100 steps with the default Weight of 4 and a final step with a Weight of 1, for a total Weight of 401,
+	// which needs a second byte to reach every step.
+	many := make([]Step, 101)
+	many[100].Weight = 1
+	tests := []struct {
+		name          string
+		steps         []Step
+		stepIndex     uint8
+		stepIndexHigh uint8
+		want          int
+	}{
+		{"unweighted 0", unweighted, 0, 0, 0},
+		{"unweighted 4", unweighted, 4, 0, 1},
+		{"unweighted 255", unweighted, 255, 0, 0},
+		{"weighted 0", weighted, 0, 0, 0},
+		{"weighted 31", weighted, 31, 0, 0},
+		{"weighted 32", weighted, 32, 0, 1},
+		{"weighted 159", weighted, 159, 0, 1},
+		{"weighted 160", weighted, 160, 0, 2},
+		{"weighted 255", weighted, 255, 0, 2},
+		{"wide 0", many, 0, 0, 0},
+		{"wide 255 0", many, 255, 0, 0},
+		{"wide 0 1", many, 0, 1, 0},
+		{"wide 0 128", many, 0, 128, 50},
+		{"wide 255 255", many, 255, 255, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chooseStep(tt.steps, plan.Call{StepIndex: tt.stepIndex, StepIndexHigh: tt.stepIndexHigh}); got != tt.want {
+				t.Errorf("chooseStep() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzerChainPre(t *testing.T) {
+	// A plan that is effectively:
+	//     x := pop()   // skipped because the stack is empty.
+	//     push(x)      // skipped because x is a return value from a skipped call.
+	//     push(0)
+	//     pop()
+	pl := plan.Plan{
+		Calls: []plan.Call{
+			{StepIndex: 0},
+			{StepIndex: 1, ArgSource: []plan.ArgSource{{SourceType: 1}}},
+			{StepIndex: 1, ArgSource: []plan.ArgSource{{SourceType: 2}}},
+			{StepIndex: 0},
+		},
+	}
+
+	for _, parallel := range []bool{false, true} {
+		t.Run(fmt.Sprintf("parallel %v", parallel), func(t *testing.T) {
+			var stack []int
+			var pushes, pops int
+			steps := []Step{
+				{
+					Name: "pop",
+					Func: func() int {
+						pops++
+						x := stack[len(stack)-1]
+						stack = stack[:len(stack)-1]
+						return x
+					},
+					Pre: func() bool { return len(stack) > 0 },
+				},
+				{
+					Name: "push",
+					Func: func(x int) {
+						pushes++
+						stack = append(stack, x)
+					},
+				},
+			}
+
+			// The bytes are the reserved first byte, then the spin, loop count, and order bytes,
+			// and finally the parallelPlan byte, where '2' means the second and third calls are parallel.
+			fz := NewFuzzer([]byte{0, 0, 0, 0, '2'})
+			if parallel {
+				fz.applyChainOpts([]ChainOpt{ChainParallel})
+			}
+			fz.chain(steps, pl)
+			if pushes != 1 {
+				t.Errorf("chain() called push %d times, want 1", pushes)
+			}
+			if pops != 1 {
+				t.Errorf("chain() called pop %d times, want 1", pops)
+			}
+		})
+	}
+}
@@ -10,8 +10,9 @@ type Plan struct {
 type Call struct {
 	// These will be filled in from the data []byte from the core fuzzing engine (go-fuzz or cmd/go)
 	// via fz.Fill.
-	StepIndex uint8       // which Step in steps does this represent, mod len(steps) (or if > len(steps ==> stop)
-	ArgSource []ArgSource // list of how to create arguments for this Step. Zero len ==> all new args. If len is > args, ignore extra.
+	StepIndex     uint8       // which Step in steps does this represent, mod len(steps) or in proportion to any Step weights
+	StepIndexHigh uint8       // with StepIndex, which Step this represents if the total Step weight is above 256. otherwise unused.
+	ArgSource     []ArgSource // list of how to create arguments for this Step. Zero len ==> all new args. If len is > args, ignore extra.
 }
 
 // ArgSource represents how to obtain values for one argument to an ExecStep.
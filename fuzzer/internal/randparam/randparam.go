@@ -48,8 +48,29 @@ var SupportedInterfaces = map[string]bool{
 // with the ability to fill in common interfaces, as well as string, []byte, and number values.
 type Fuzzer struct {
 	fzgoSrc *randSource
+
+	// dictStrings and dictInts are optional dictionary values, such as literals from the code under test.
+	dictStrings []string
+	dictInts    []int64
+
+	// maxElements bounds the number of elements for slices and maps other than []byte. 0 means defaultMaxElements.
+	maxElements int
 }
 
+// defaultMaxElements is the default bound on the number of elements for slices and maps other than []byte.
+const defaultMaxElements = 10
+
+const (
+	// dictStringMarker is a size field for a string or []byte that instead indicates
+	// the next byte selects an entry from the string dictionary, if we have one.
+	dictStringMarker = 0xFE
+
+	// dictIntThreshold is the minimum value of the control byte drawn before an integer
+	// that indicates the next byte selects an entry from the integer dictionary, if we have one.
+	// In other words, a dictionary entry is used for roughly 1 in 8 random integers.
+	dictIntThreshold = 0xE0
+)
+
 // NewFuzzer returns a *Fuzzer, initialized with the []byte as an input stream for drawing values via rand.Rand.
 func NewFuzzer(data []byte) *Fuzzer {
 	// create our random data stream that fill use data []byte for results.
@@ -81,6 +102,36 @@ func NewFuzzer(data []byte) *Fuzzer {
 	return f
 }
 
+// SetDictionary sets values for Fill to choose from when filling strings, []byte, and integers,
+// which helps reach code that compares against specific values.
+// When there are any string values, a size field of 0xFE for a string or []byte means the
+// next byte selects a dictionary entry. When there are any integer values, each integer is
+// preceded by a control byte, where a value of 0xE0 or more means the next byte
+// selects a dictionary entry.
+func (f *Fuzzer) SetDictionary(strs []string, ints []int64) {
+	f.dictStrings = strs
+	f.dictInts = ints
+}
+
+// SetMaxElements sets the bound on the number of elements Fill creates for slices and maps other than []byte,
+// which are filled with fewer than n elements. The default is 10. A bound above 256 draws
+// two bytes rather than one for each size.
+func (f *Fuzzer) SetMaxElements(n int) {
+	f.maxElements = n
+}
+
+// elementCount draws the number of elements for a slice or map other than []byte.
+func (f *Fuzzer) elementCount() int {
+	max := f.maxElements
+	if max <= 0 {
+		max = defaultMaxElements
+	}
+	if max <= 256 {
+		return int(f.numericDraw(reflect.Uint8) % uint64(max))
+	}
+	return int(f.numericDraw(reflect.Uint16) % uint64(max))
+}
+
 // Remaining reports how many bytes remain in our original input []byte.
 func (f *Fuzzer) Remaining() int {
 	return f.fzgoSrc.Remaining()
@@ -281,6 +332,17 @@ func (f *Fuzzer) fillByteSlice(ptr *[]byte) {
 			fmt.Println("sizeField:", sizeField)
 		}
 
+		// If we have a dictionary, 0xFE indicates the next byte selects a dictionary entry.
+		if sizeField == dictStringMarker && len(f.dictStrings) > 0 {
+			if f.Remaining() == 0 {
+				*ptr = nil
+				return
+			}
+			i := int(f.fzgoSrc.Byte()) % len(f.dictStrings)
+			*ptr = []byte(f.dictStrings[i])
+			return
+		}
+
 		// If we don't have enough data, we want to
 		// *not* use the size field or the data after sizeField,
 		// in order to work better with sonar.
@@ -495,11 +557,11 @@ func (f *Fuzzer) fill(v reflect.Value, depth int, opts fillOpts) {
 	switch v.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		// recall, rune is type alias of int32.
-		bits := f.numericDraw(v.Kind())
+		bits := f.intDraw(v.Kind())
 		v.SetInt(int64(bits))
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		// recall, byte is type alias of uint8.
-		bits := f.numericDraw(v.Kind())
+		bits := f.intDraw(v.Kind())
 		v.SetUint(bits)
 	case reflect.Float32:
 		bits := f.numericDraw(v.Kind())
@@ -517,8 +579,7 @@ func (f *Fuzzer) fill(v reflect.Value, depth int, opts fillOpts) {
 		f.fillString(&s)
 		v.SetString(s)
 	case reflect.Bool:
-		var b byte
-		f.Fill(&b)
+		b := f.numericDraw(reflect.Uint8)
 		if b < 128 {
 			v.SetBool(false)
 		} else {
@@ -547,22 +608,17 @@ func (f *Fuzzer) fill(v reflect.Value, depth int, opts fillOpts) {
 			}
 		} else {
 			// TODO: favor smaller slice sizes?
-			// TODO: make slice size for non-byte slices more controllable via config. max is 10 for now.
-			var size byte
-			f.Fill(&size)
-			size %= 10
-			v.Set(reflect.MakeSlice(v.Type(), int(size), int(size)))
+			size := f.elementCount()
+			v.Set(reflect.MakeSlice(v.Type(), size, size))
 			for i := 0; i < v.Len(); i++ {
 				f.fill(v.Index(i), depth, opts)
 			}
 		}
 	case reflect.Map:
-		// TODO: similar to slice - favor smaller, more configurable
-		var size byte
-		f.Fill(&size)
-		size %= 10
-		v.Set(reflect.MakeMapWithSize(v.Type(), int(size)))
-		for i := 0; i < int(size); i++ {
+		// TODO: similar to slice - favor smaller
+		size := f.elementCount()
+		v.Set(reflect.MakeMapWithSize(v.Type(), size))
+		for i := 0; i < size; i++ {
 			key := reflect.New(v.Type().Key()).Elem()
 			value := reflect.New(v.Type().Elem()).Elem()
 			f.fill(key, depth, opts)
@@ -599,6 +655,32 @@ func (f *Fuzzer) fill(v reflect.Value, depth int, opts fillOpts) {
 	}
 }
 
+// ControlUint8 returns a uint8 drawn from the input data without consulting any dictionary,
+// which is used for bytes that control how other values are interpreted.
+// It returns 0 if there are no bytes remaining.
+func (f *Fuzzer) ControlUint8() uint8 {
+	return uint8(f.numericDraw(reflect.Uint8))
+}
+
+// ControlUint64 is like ControlUint8 for a uint64.
+func (f *Fuzzer) ControlUint64() uint64 {
+	return f.numericDraw(reflect.Uint64)
+}
+
+// intDraw is like numericDraw for integer kinds, but if we have an integer dictionary,
+// it first draws a control byte that determines whether to use a dictionary entry.
+func (f *Fuzzer) intDraw(k reflect.Kind) (bits uint64) {
+	if len(f.dictInts) == 0 || f.Remaining() == 0 {
+		return f.numericDraw(k)
+	}
+	control := f.fzgoSrc.Byte()
+	if control < dictIntThreshold || f.Remaining() == 0 {
+		return f.numericDraw(k)
+	}
+	i := int(f.fzgoSrc.Byte()) % len(f.dictInts)
+	return uint64(f.dictInts[i])
+}
+
 // numericDraw calculates the bytes that should be
 // used for a given numeric reflect.Value. If there are not enough bytes
 // remaining in our data []byte, returns 0. Otherwise, returns
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"io"
 	"io/ioutil"
+	"math"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -206,6 +207,101 @@ func TestFuzzingBasicTypes(t *testing.T) {
 	})
 }
 
+func TestFuzzingDictionary(t *testing.T) {
+	strs := []string{"magic", "v1.2.3"}
+	ints := []int64{1234, -1}
+
+	t.Run("string - dictionary marker selects entry", func(t *testing.T) {
+		input := []byte{0x0, 0xFE, 0x3}
+		want := "v1.2.3"
+
+		fuzzer := NewFuzzer(input)
+		fuzzer.SetDictionary(strs, ints)
+		var got string
+		fuzzer.Fill2(&got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("fuzzer.Fill() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("string - 0xFE is a length without a dictionary", func(t *testing.T) {
+		input := append([]byte{0x0, 0xFE}, make([]byte, 0xFE)...)
+		want := string(make([]byte, 0xFE))
+
+		fuzzer := NewFuzzer(input)
+		var got string
+		fuzzer.Fill2(&got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("fuzzer.Fill() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("[]byte - dictionary marker selects entry", func(t *testing.T) {
+		input := []byte{0x0, 0xFE, 0x0}
+		want := []byte("magic")
+
+		fuzzer := NewFuzzer(input)
+		fuzzer.SetDictionary(strs, ints)
+		var got []byte
+		fuzzer.Fill2(&got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("fuzzer.Fill() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("int16 - control byte selects entry", func(t *testing.T) {
+		input := []byte{0x0, 0xE0, 0x0}
+		want := int16(1234)
+
+		fuzzer := NewFuzzer(input)
+		fuzzer.SetDictionary(strs, ints)
+		var got int16
+		fuzzer.Fill2(&got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("fuzzer.Fill() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("uint64 - control byte selects negative entry", func(t *testing.T) {
+		input := []byte{0x0, 0xFF, 0x1}
+		want := uint64(math.MaxUint64)
+
+		fuzzer := NewFuzzer(input)
+		fuzzer.SetDictionary(strs, ints)
+		var got uint64
+		fuzzer.Fill2(&got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("fuzzer.Fill() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("int32 - control byte below threshold draws bytes", func(t *testing.T) {
+		input := []byte{0x0, 0x0, 0x42, 0x0, 0x0, 0x0}
+		want := int32(0x42)
+
+		fuzzer := NewFuzzer(input)
+		fuzzer.SetDictionary(strs, ints)
+		var got int32
+		fuzzer.Fill2(&got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("fuzzer.Fill() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("bool - no control byte", func(t *testing.T) {
+		input := []byte{0x0, 0x80}
+		want := true
+
+		fuzzer := NewFuzzer(input)
+		fuzzer.SetDictionary(strs, ints)
+		var got bool
+		fuzzer.Fill2(&got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("fuzzer.Fill() mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
 func TestFuzzingInterfaces(t *testing.T) {
 	t.Run("io.Reader - 8 byte length, 8 bytes of string input", func(t *testing.T) {
 		input := append([]byte{0x0, 0x8}, []byte("12345678")...)
@@ -341,3 +437,28 @@ func TestFuzzingStructs(t *testing.T) {
 		}
 	})
 }
+
+func TestFuzzingMaxElements(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxElements int
+		input       []byte
+		wantLen     int
+	}{
+		{"default", 0, []byte{0x0, 12}, 2},
+		{"smaller", 3, []byte{0x0, 12}, 0},
+		{"larger", 100, []byte{0x0, 42}, 42},
+		{"two bytes", 1000, []byte{0x0, 0x01, 0x02}, 513},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fuzzer := NewFuzzer(tt.input)
+			fuzzer.SetMaxElements(tt.maxElements)
+			var got []bool
+			fuzzer.Fill2(&got)
+			if len(got) != tt.wantLen {
+				t.Errorf("fuzzer.Fill() len = %d, want %d", len(got), tt.wantLen)
+			}
+		})
+	}
+}
@@ -21,12 +21,15 @@ import (
 // including the coverage-guided evolution and tail trim minimization, which is currently
 // the first minimization technique for both cmd/go and dvyukov/go-fuzz.
 //
+// maxCalls is the maximum number of calls in the Plan, which is set via ChainMaxCalls.
+// The same bytes result in the same Plan for a given maxCalls.
+//
 // It is the caller's responsibility to track how many bytes are consumed.
 // E.g., caller could do:
 //   buf := bytes.NewReader(data)
 //   pl := unmarshalPlan(buf, ...)
 //   used := len(data) - buf.Len()
-func unmarshalPlan(r io.Reader, steps []Step) plan.Plan {
+func unmarshalPlan(r io.Reader, steps []Step, maxCalls int) plan.Plan {
 	pl := plan.Plan{}
 
 	var callCountByte uint8
@@ -43,12 +46,25 @@ func unmarshalPlan(r io.Reader, steps []Step) plan.Plan {
 		callCount = 4
 	case callCountByte < 192:
 		callCount = 5
-	default:
-		// TODO: Current max calls in our plan is 10. Probably make this configurable.
-		// Note that a loop means we might execute more than 10 calls total (e.g., loop of 256 with
+	case maxCalls <= 64:
+		// Note that a loop means we might execute more than maxCalls calls total (e.g., loop of 256 with
 		// callCount of 10 would mean 2560 total calls executed).
-		callCount = int(callCountByte%10) + 1
+		callCount = int(callCountByte)%maxCalls + 1
+	default:
+		// The 64 values left in callCountByte are not enough to cover all counts up to maxCalls,
+		// so we also read a second byte.
+		var b uint8
+		if err := binary.Read(r, binary.LittleEndian, &b); err != nil {
+			return pl
+		}
+		callCount = (int(callCountByte-192)<<8|int(b))%maxCalls + 1
 	}
+	if callCount > maxCalls {
+		callCount = maxCalls
+	}
+
+	// With enough total Step weight, each call also has a second byte to select its Step.
+	wide := wideStepIndex(steps)
 
 	// skip GoroutineOrdering
 	for i := 0; i < callCount; i++ {
@@ -67,9 +83,15 @@ func unmarshalPlan(r io.Reader, steps []Step) plan.Plan {
 			// We don't use a partially read Call.
 			break
 		}
+		if wide {
+			err = binary.Read(r, binary.LittleEndian, &call.StepIndexHigh)
+			if err != nil {
+				break
+			}
+		}
 
 		// Based on the StepIndex we just read, compute the actual index into the user's Step list.
-		s := int(call.StepIndex) % len(steps)
+		s := chooseStep(steps, call)
 		fv := mustFunc(steps[s].Func)
 		ft := fv.Type()
 
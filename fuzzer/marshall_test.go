@@ -2,6 +2,7 @@ package fuzzer
 
 import (
 	"bytes"
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -139,7 +140,7 @@ func TestUnmarshalPlan(t *testing.T) {
 			}
 
 			buf := bytes.NewBuffer(tt.data)
-			gotPlan := unmarshalPlan(buf, steps)
+			gotPlan := unmarshalPlan(buf, steps, defaultMaxCalls)
 			if diff := cmp.Diff(tt.wantPlan, gotPlan); diff != "" {
 				t.Errorf("unmarshalPlan() mismatch (-want +got):\n%s", diff)
 			}
@@ -151,3 +152,65 @@ func TestUnmarshalPlan(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalPlanMaxCalls(t *testing.T) {
+	tests := []struct {
+		name      string
+		countData []byte // the bytes that determine the count of calls
+		maxCalls  int
+		wantCalls int
+	}{
+		{"default favors 3", []byte{0}, defaultMaxCalls, 3},
+		{"default favors 5", []byte{191}, defaultMaxCalls, 5},
+		{"default max", []byte{199}, defaultMaxCalls, 10},
+		{"default wraps", []byte{200}, defaultMaxCalls, 1},
+		{"small max caps favored count", []byte{191}, 2, 2},
+		{"medium max", []byte{255}, 64, 64},
+		{"large max reads second byte", []byte{255, 255}, 1000, 384},
+		{"large max low", []byte{192, 0}, 1000, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := []Step{{Name: "step", Func: func() {}}}
+
+			// Each call only needs one byte for its StepIndex.
+			data := append(append([]byte(nil), tt.countData...), make([]byte, 2000)...)
+			pl := unmarshalPlan(bytes.NewReader(data), steps, tt.maxCalls)
+			if len(pl.Calls) != tt.wantCalls {
+				t.Errorf("unmarshalPlan() returned %d calls, want %d", len(pl.Calls), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestUnmarshalPlanWideStepIndex(t *testing.T) {
+	// 100 steps with the default Weight and one with a Weight of 1 need a second byte per call,
+	// while the same steps without any Weight set do not.
+	weighted := make([]Step, 101)
+	for i := range weighted {
+		weighted[i] = Step{Name: fmt.Sprint(i), Func: func() {}}
+	}
+	weighted[100].Weight = 1
+	unweighted := append([]Step(nil), weighted...)
+	unweighted[100].Weight = 0
+
+	// A count of 3 calls, followed by the step bytes.
+	data := []byte{0, 1, 2, 3, 4, 255, 255}
+	pl := unmarshalPlan(bytes.NewReader(data), weighted, defaultMaxCalls)
+	want := []plan.Call{
+		{StepIndex: 1, StepIndexHigh: 2},
+		{StepIndex: 3, StepIndexHigh: 4},
+		{StepIndex: 255, StepIndexHigh: 255},
+	}
+	if diff := cmp.Diff(want, pl.Calls); diff != "" {
+		t.Errorf("unmarshalPlan() mismatch (-want +got):\n%s", diff)
+	}
+	if got := chooseStep(weighted, pl.Calls[2]); got != 100 {
+		t.Errorf("chooseStep() = %d, want 100", got)
+	}
+
+	pl = unmarshalPlan(bytes.NewReader(data), unweighted, defaultMaxCalls)
+	if len(pl.Calls) != 3 || pl.Calls[1].StepIndex != 2 || pl.Calls[1].StepIndexHigh != 0 {
+		t.Errorf("unmarshalPlan() without weights = %+v, want one byte per call", pl.Calls)
+	}
+}
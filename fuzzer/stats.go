@@ -0,0 +1,175 @@
+package fuzzer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// statsInterval is how often we write chain statistics, which are only collected with FZDEBUG=stats=<path>.
+const statsInterval = 10 * time.Second
+
+// chainStats holds the statistics for all chains executed by this process, and
+// is nil unless statistics are enabled via FZDEBUG=stats=<path>.
+var chainStats *stats
+
+// stats accumulates counts of what happened while executing chains, which help
+// show when a Step is effectively unreachable and a harness might need tuning.
+// The methods of stats are no-ops for a nil *stats.
+type stats struct {
+	mu             sync.Mutex
+	start          time.Time
+	lastWrite      time.Time
+	chains         int64
+	parallelChains int64
+	steps          map[string]*stepStats
+}
+
+// stepStats are the statistics for one Step, keyed by the Step's name.
+type stepStats struct {
+	calls       int64 // completed calls.
+	skipped     int64 // calls skipped due to a false precondition or a stopped chain.
+	panics      int64
+	errors      int64         // calls that returned a non-nil error as the last return value.
+	argReuse    int64         // arguments that reused an input to an earlier call.
+	returnReuse int64         // arguments that used a return value from an earlier call.
+	elapsed     time.Duration // total time spent in completed calls.
+}
+
+func newStats() *stats {
+	return &stats{start: time.Now(), steps: make(map[string]*stepStats)}
+}
+
+// step returns the statistics for the Step named name. s.mu must be held.
+func (s *stats) step(name string) *stepStats {
+	ss, ok := s.steps[name]
+	if !ok {
+		ss = &stepStats{}
+		s.steps[name] = ss
+	}
+	return ss
+}
+
+// recordChain records that we are executing a chain, including recording all of its
+// Steps so that a Step that is never called still shows up with zero calls.
+func (s *stats) recordChain(steps []Step, sequential bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chains++
+	if !sequential {
+		s.parallelChains++
+	}
+	for _, step := range steps {
+		s.step(step.Name)
+	}
+}
+
+// recordSkip records that we skipped call ec.
+func (s *stats) recordSkip(ec execCall) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.step(ec.name).skipped++
+}
+
+// recordCall records that call ec returned ret after running for elapsed.
+func (s *stats) recordCall(ec execCall, ret []reflect.Value, elapsed time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ss := s.step(ec.name)
+	ss.calls++
+	ss.elapsed += elapsed
+	if stepError(ret) != nil {
+		ss.errors++
+	}
+	for _, arg := range ec.args {
+		switch {
+		case arg.useReturnVal:
+			ss.returnReuse++
+		case arg.reused:
+			ss.argReuse++
+		}
+	}
+}
+
+// recordPanic records that call ec panicked, and writes our statistics
+// because the panic likely means the process is about to exit.
+func (s *stats) recordPanic(ec execCall) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.step(ec.name).panics++
+	s.mu.Unlock()
+	s.write()
+}
+
+// maybeWrite writes our statistics if we have not written them in the last statsInterval.
+func (s *stats) maybeWrite() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	due := time.Since(s.lastWrite) >= statsInterval
+	s.mu.Unlock()
+	if due {
+		s.write()
+	}
+}
+
+// write writes our statistics to debugStatsPath, which is "stderr" or a file path.
+// Each fuzzing worker process has its own statistics, so we append the process ID to a file path.
+func (s *stats) write() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastWrite = time.Now()
+	if debugStatsPath == "stderr" {
+		s.report(os.Stderr)
+		return
+	}
+	f, err := os.Create(fmt.Sprintf("%s.%d", debugStatsPath, os.Getpid()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fzgen: failed to write chain stats: %v\n", err)
+		return
+	}
+	defer f.Close()
+	s.report(f)
+}
+
+// report writes a table of our statistics to w. s.mu must be held. The result is similar to:
+//    fzgen: chain stats for process 1234 after 1m0s: 5000 chains, 1250 parallel
+//    step            calls  skipped  panics  errors  arg reuse  return reuse  time
+//    Fuzz_Set_Add    4000   0        0       0       1100       20            2ms
+//    Fuzz_Set_Close  0      0        0       0       0          0             0s
+func (s *stats) report(w io.Writer) {
+	fmt.Fprintf(w, "fzgen: chain stats for process %d after %v: %d chains, %d parallel\n",
+		os.Getpid(), time.Since(s.start).Round(time.Second), s.chains, s.parallelChains)
+
+	names := make([]string, 0, len(s.steps))
+	for name := range s.steps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "step\tcalls\tskipped\tpanics\terrors\targ reuse\treturn reuse\ttime")
+	for _, name := range names {
+		ss := s.steps[name]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%v\n",
+			name, ss.calls, ss.skipped, ss.panics, ss.errors, ss.argReuse, ss.returnReuse, ss.elapsed)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,71 @@
+package fuzzer
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thepudds/fzgen/fuzzer/internal/plan"
+)
+
+func TestChainStats(t *testing.T) {
+	chainStats = newStats()
+	debugStatsPath = filepath.Join(t.TempDir(), "stats")
+	defer func() { chainStats, debugStatsPath = nil, "" }()
+
+	// A plan that is effectively:
+	//     x := step1(0)
+	//     step2(x)      // returns an error
+	//     step1(0)      // reuse input arg
+	//     step3()       // skipped because its precondition is false
+	// and step4 is never called.
+	pl := plan.Plan{
+		Calls: []plan.Call{
+			{StepIndex: 0, ArgSource: []plan.ArgSource{{SourceType: 2}}},
+			{StepIndex: 1, ArgSource: []plan.ArgSource{{SourceType: 1}}},
+			{StepIndex: 0, ArgSource: []plan.ArgSource{{SourceType: 0}}},
+			{StepIndex: 2},
+		},
+	}
+	steps := []Step{
+		{Name: "step1", Func: func(a int) int { return a }},
+		{Name: "step2", Func: func(a int) error { return errors.New("bad") }},
+		{Name: "step3", Func: func() {}, Pre: func() bool { return false }},
+		{Name: "step4", Func: func() {}},
+	}
+
+	fz := NewFuzzer([]byte{})
+	fz.chain(steps, pl)
+
+	want := map[string]stepStats{
+		"step1": {calls: 2, argReuse: 1},
+		"step2": {calls: 1, errors: 1, returnReuse: 1},
+		"step3": {skipped: 1},
+		"step4": {},
+	}
+	if chainStats.chains != 1 || chainStats.parallelChains != 0 {
+		t.Errorf("chain() recorded %d chains and %d parallel chains, want 1 and 0", chainStats.chains, chainStats.parallelChains)
+	}
+	for name, w := range want {
+		got := *chainStats.steps[name]
+		got.elapsed = 0
+		if got != w {
+			t.Errorf("chain() recorded stats for %s = %+v, want %+v", name, got, w)
+		}
+	}
+
+	// The first chain in the process writes a report.
+	b, err := ioutil.ReadFile(fmt.Sprintf("%s.%d", debugStatsPath, os.Getpid()))
+	if err != nil {
+		t.Fatalf("failed to read stats: %v", err)
+	}
+	for _, s := range []string{"1 chains, 0 parallel", "step4", "return reuse"} {
+		if !strings.Contains(string(b), s) {
+			t.Errorf("chain() wrote stats %q, want it to contain %q", b, s)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/thepudds/fzgen/gen/internal/mod"
+)
+
+// config is the format of an fzgen config file, which is typically checked in
+// at the root of a module as fzgen.json and used via 'fzgen -config=fzgen.json ./...'.
+// A sample config file:
+//
+//	{
+//	  "func": "^[A-Z]",
+//	  "exclude": ["Deprecated"],
+//	  "packages": [
+//	    {"pattern": "./cache/...", "chain": true, "parallel": true, "ctor": "^New$"},
+//	    {"pattern": "./parse", "params": {"Parse.s": ["\"1.2.3\"", "\"v1\""]}}
+//	  ]
+//	}
+//
+// The top-level settings apply to all packages. A target package uses the settings from
+// the first entry in packages whose pattern matches it, which override the top-level settings.
+// Any command line flags that are explicitly set override the settings in the config file.
+type config struct {
+	genSettings
+	Packages []packageConfig `json:"packages"`
+
+	dir     string            // directory containing the config file, used for relative package patterns
+	matches []map[string]bool // import paths matched by the pattern for each entry in Packages
+}
+
+// packageConfig holds the settings for the packages matching a package pattern.
+type packageConfig struct {
+	Pattern string `json:"pattern"`
+	genSettings
+}
+
+// genSettings holds the settings used when generating wrappers for one target package.
+// Unset fields are the zero value (or nil for the pointer fields), which allows a config file's
+// package entries to override its top-level settings, and explicit flags to override both.
+type genSettings struct {
+	Func       string   `json:"func,omitempty"`       // function regexp
+	Ctor       string   `json:"ctor,omitempty"`       // constructor regexp
+	Exclude    []string `json:"exclude,omitempty"`    // regexps for functions to skip, matching names like "Parse" or "Set.Add"
+	Chain      *bool    `json:"chain,omitempty"`      // emit chains rather than independent wrappers
+	Parallel   *bool    `json:"parallel,omitempty"`   // allow chains to run in parallel
+	Unexported *bool    `json:"unexported,omitempty"` // include unexported functions
+	CtorInject *bool    `json:"ctorinject,omitempty"` // insert constructors when wrapping methods
+	Seeds      *bool    `json:"seeds,omitempty"`      // emit seed corpus entries harvested from existing tests
+	Dict       *bool    `json:"dict,omitempty"`       // emit a dictionary of literals and constants for fz.Fill
+	Output     string   `json:"output,omitempty"`     // output file name
+	TypeArgs   string   `json:"typeargs,omitempty"`   // comma-separated types for instantiating generics
+	Diff       string   `json:"diff,omitempty"`       // reference package to compare against in differential wrappers
+	Invariants string   `json:"invariants,omitempty"` // comma-separated methods checking a chain target's consistency, or "none"
+
+	// CtorFallback is how a chain's target is created when there is no matching constructor:
+	// "none" to skip the type, "zero" for a zero value, or "fill" for a value populated via fz.Fill.
+	CtorFallback string `json:"ctorfallback,omitempty"`
+
+	// Params holds value hints for parameters, which are emitted as seed corpus entries
+	// via f.Add for wrappers that cmd/go can fuzz natively. The keys are parameter names,
+	// optionally prefixed by a function name such as "Parse.s" or "Set.Add.v".
+	// The values are Go expressions such as "42" or "\"hello\"".
+	Params map[string][]string `json:"params,omitempty"`
+}
+
+// defaultSettings returns the settings used if neither a config file nor a flag sets a value.
+// These match the defaults for the corresponding flags, except Output is left empty so
+// that the default output file name can depend on whether we are emitting chains.
+func defaultSettings() genSettings {
+	return genSettings{
+		Func:       ".",
+		Ctor:       ".",
+		Chain:      boolPtr(false),
+		Parallel:   boolPtr(false),
+		Unexported: boolPtr(false),
+		CtorInject: boolPtr(true),
+		Seeds:      boolPtr(true),
+		Dict:       boolPtr(true),
+		TypeArgs:   defaultTypeArgs,
+		Invariants: defaultInvariants,
+
+		CtorFallback: ctorFallbackNone,
+	}
+}
+
+// merge returns a copy of s with any fields set in override replacing the values in s.
+// Exclude lists are combined, as are Params.
+func (s genSettings) merge(override genSettings) genSettings {
+	result := s
+	if override.Func != "" {
+		result.Func = override.Func
+	}
+	if override.Ctor != "" {
+		result.Ctor = override.Ctor
+	}
+	if override.Output != "" {
+		result.Output = override.Output
+	}
+	if override.TypeArgs != "" {
+		result.TypeArgs = override.TypeArgs
+	}
+	if override.CtorFallback != "" {
+		result.CtorFallback = override.CtorFallback
+	}
+	if override.Diff != "" {
+		result.Diff = override.Diff
+	}
+	if override.Invariants != "" {
+		result.Invariants = override.Invariants
+	}
+	for _, p := range []struct{ dst, src **bool }{
+		{&result.Chain, &override.Chain},
+		{&result.Parallel, &override.Parallel},
+		{&result.Unexported, &override.Unexported},
+		{&result.CtorInject, &override.CtorInject},
+		{&result.Seeds, &override.Seeds},
+		{&result.Dict, &override.Dict},
+	} {
+		if *p.src != nil {
+			*p.dst = *p.src
+		}
+	}
+	result.Exclude = append(append([]string(nil), s.Exclude...), override.Exclude...)
+	if len(override.Params) > 0 {
+		result.Params = make(map[string][]string)
+		for k, v := range s.Params {
+			result.Params[k] = v
+		}
+		for k, v := range override.Params {
+			result.Params[k] = v
+		}
+	}
+	return result
+}
+
+// checkCtorFallback returns an error if fallback is not a valid CtorFallback setting.
+func checkCtorFallback(fallback string) error {
+	switch fallback {
+	case ctorFallbackNone, ctorFallbackZero, ctorFallbackFill:
+		return nil
+	}
+	return fmt.Errorf("invalid ctorfallback %q: must be none, zero, or fill", fallback)
+}
+
+// outFile returns the output file name, defaulting to autofuzz_test.go or autofuzzchain_test.go,
+// or autofuzzdiff_test.go or autofuzzdiffchain_test.go for differential wrappers.
+func (s genSettings) outFile() string {
+	out := s.Output
+	if out != "" {
+		return out
+	}
+	switch {
+	case s.Diff != "" && *s.Chain:
+		return "autofuzzdiffchain_test.go"
+	case s.Diff != "":
+		return "autofuzzdiff_test.go"
+	case *s.Chain:
+		return "autofuzzchain_test.go"
+	default:
+		return "autofuzz_test.go"
+	}
+}
+
+// filter returns a copy of p that only contains the functions and constructors selected by s.
+func (s genSettings) filter(p *pkg) (*pkg, error) {
+	funcRe, err := regexp.Compile(s.Func)
+	if err != nil {
+		return nil, err
+	}
+	ctorRe, err := regexp.Compile(s.Ctor)
+	if err != nil {
+		return nil, err
+	}
+	var excludeRes []*regexp.Regexp
+	for _, e := range s.Exclude {
+		re, err := regexp.Compile(e)
+		if err != nil {
+			return nil, err
+		}
+		excludeRes = append(excludeRes, re)
+	}
+	keep := func(function mod.Func, re *regexp.Regexp) bool {
+		if !*s.Unexported && !isExportedFunc(function.TypesFunc) {
+			return false
+		}
+		for _, excludeRe := range excludeRes {
+			if excludeRe.MatchString(function.FuncName) || excludeRe.MatchString(funcDisplayName(function)) {
+				return false
+			}
+		}
+		return re.MatchString(function.FuncName)
+	}
+
+	result := &pkg{pkgPath: p.pkgPath}
+	for _, function := range p.functions {
+		if keep(function, funcRe) {
+			result.functions = append(result.functions, function)
+		}
+	}
+	for _, function := range p.constructors {
+		if keep(function, ctorRe) {
+			result.constructors = append(result.constructors, function)
+		}
+	}
+	return result, nil
+}
+
+// loadConfig reads and validates a config file.
+func loadConfig(path string) (*config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c config
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %v", path, err)
+	}
+	if err := c.genSettings.validate(); err != nil {
+		return nil, fmt.Errorf("config file %s: %v", path, err)
+	}
+	for i, pc := range c.Packages {
+		if pc.Pattern == "" {
+			return nil, fmt.Errorf("config file %s: package entry %d has no pattern", path, i)
+		}
+		if err := pc.genSettings.validate(); err != nil {
+			return nil, fmt.Errorf("config file %s: package pattern %q: %v", path, pc.Pattern, err)
+		}
+	}
+
+	c.dir, err = filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// validate checks that the regexps in s compile and the output is a file name.
+func (s genSettings) validate() error {
+	patterns := append([]string{s.Func, s.Ctor}, s.Exclude...)
+	for _, p := range patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return err
+		}
+	}
+	if s.Output != "" && hasPath(s.Output) {
+		return fmt.Errorf("output %q must be a file name and not a path", s.Output)
+	}
+	return nil
+}
+
+// settingsFor returns the config file settings for a package, which are the
+// top-level settings overridden by the first package entry that matches pkgPath.
+// buildFlags are passed to the go command when resolving package patterns.
+func (c *config) settingsFor(pkgPath string, buildFlags []string) (genSettings, error) {
+	if c.matches == nil {
+		// Resolve each package pattern relative to the directory of the config file.
+		for _, pc := range c.Packages {
+			args := append(append([]string{"-e", "-f", "{{.ImportPath}}"}, buildFlags...), pc.Pattern)
+			pkgPaths, err := goList(c.dir, args...)
+			if err != nil {
+				return genSettings{}, err
+			}
+			m := make(map[string]bool)
+			for _, p := range pkgPaths {
+				m[p] = true
+			}
+			c.matches = append(c.matches, m)
+		}
+	}
+	for i, pc := range c.Packages {
+		if c.matches[i][pkgPath] {
+			return c.genSettings.merge(pc.genSettings), nil
+		}
+	}
+	return c.genSettings, nil
+}
+
+// funcDisplayName returns the name of a function for use in config files,
+// such as "Parse" for a function or "Set.Add" for a method.
+func funcDisplayName(function mod.Func) string {
+	if n := receiver(function.TypesFunc); n != nil {
+		return n.Obj().Name() + "." + function.FuncName
+	}
+	return function.FuncName
+}
+
+// paramHints returns the value hints from a config file for one parameter of a function.
+// A hint keyed by the qualified parameter name (e.g., "Set.Add.v" or "Parse.s")
+// takes precedence over a hint keyed only by the parameter name (e.g., "s").
+func paramHints(hints map[string][]string, f *types.Func, v *types.Var) []string {
+	if len(hints) == 0 {
+		return nil
+	}
+	name := f.Name()
+	if n := receiver(f); n != nil {
+		name = n.Obj().Name() + "." + name
+	}
+	if h, ok := hints[name+"."+v.Name()]; ok {
+		return h
+	}
+	return hints[v.Name()]
+}
+
+// zeroValue returns a typed zero value expression for a type natively supported by cmd/go fuzzing,
+// suitable for use as an argument to f.Add, such as 'int(0)' or 'string("")'.
+func zeroValue(t types.Type, typ string) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return fmt.Sprintf("%s(false)", typ)
+		case u.Info()&types.IsString != 0:
+			return fmt.Sprintf("%s(\"\")", typ)
+		default:
+			return fmt.Sprintf("%s(0)", typ)
+		}
+	default:
+		// []byte
+		return fmt.Sprintf("%s{}", typ)
+	}
+}
+
+// emitParamHints emits f.Add calls for any value hints for the parameters.
+// Each f.Add sets one hinted parameter and uses zero values for the others.
+func emitParamHints(emit emitFunc, hints map[string][]string, sources []paramSource, paramReprs []paramRepr) {
+	for i, p := range paramReprs {
+		for _, hint := range paramHints(hints, sources[i].f, p.v) {
+			var args []string
+			for j, other := range paramReprs {
+				if i == j {
+					args = append(args, fmt.Sprintf("%s(%s)", other.typ, hint))
+				} else {
+					args = append(args, zeroValue(other.v.Type(), other.typ))
+				}
+			}
+			emit("\tf.Add(%s)\n", strings.Join(args, ", "))
+		}
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
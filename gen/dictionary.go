@@ -0,0 +1,171 @@
+package gen
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// dictVarName, chainDictVarName, and diffDictVarName are the names of the variable holding the emitted
+	// fuzzer.Dictionary, which differ so that independent wrappers, chain wrappers, and differential
+	// wrappers can be in the same package.
+	dictVarName      = "fuzzDictionary"
+	chainDictVarName = "fuzzChainDictionary"
+	diffDictVarName  = "fuzzDiffDictionary"
+
+	// maxDictEntries caps the strings and the integers in a dictionary.
+	// fz.Fill selects an entry with one byte, so more would not be reachable.
+	maxDictEntries = 256
+
+	// maxDictStringLen skips longer string literals, which are more likely to
+	// be something like a template or usage message than a value compared against.
+	maxDictStringLen = 64
+)
+
+// dictionary holds the literals and constants from a target package
+// that we emit as a fuzzer.Dictionary.
+type dictionary struct {
+	strings []string
+	ints    []int64
+
+	seen map[interface{}]bool
+}
+
+func (d *dictionary) empty() bool {
+	return d == nil || (len(d.strings) == 0 && len(d.ints) == 0)
+}
+
+func (d *dictionary) hasStrings() bool { return d != nil && len(d.strings) > 0 }
+
+func (d *dictionary) hasInts() bool { return d != nil && len(d.ints) > 0 }
+
+// add adds a constant value to the dictionary if it is a useful string or integer.
+// Integers like 0 and 1 are easy for a fuzzer to find on its own and are skipped.
+func (d *dictionary) add(val constant.Value) {
+	switch val.Kind() {
+	case constant.String:
+		s := constant.StringVal(val)
+		if s == "" || len(s) > maxDictStringLen || d.seen[s] || len(d.strings) >= maxDictEntries {
+			return
+		}
+		d.seen[s] = true
+		d.strings = append(d.strings, s)
+	case constant.Int:
+		i, exact := constant.Int64Val(val)
+		if !exact || (i >= -1 && i <= 1) || d.seen[i] || len(d.ints) >= maxDictEntries {
+			return
+		}
+		d.seen[i] = true
+		d.ints = append(d.ints, i)
+	}
+}
+
+// harvestDictionary collects the values of the constants declared in typesPkg, followed by
+// string, integer, and rune literals from the non-test .go files in dir. Import paths and struct tags are skipped.
+func harvestDictionary(dir string, typesPkg *types.Package) (*dictionary, error) {
+	d := &dictionary{seen: make(map[interface{}]bool)}
+
+	// Start with the declared constants, which includes evaluating any iota or other constant expressions.
+	scope := typesPkg.Scope()
+	for _, name := range scope.Names() {
+		if c, ok := scope.Lookup(name).(*types.Const); ok {
+			d.add(c.Val())
+		}
+	}
+
+	// Next, look for literals within the code.
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		tags := make(map[*ast.BasicLit]bool)
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ImportSpec:
+				return false
+			case *ast.Field:
+				if n.Tag != nil {
+					tags[n.Tag] = true
+				}
+			case *ast.UnaryExpr:
+				if lit, ok := n.X.(*ast.BasicLit); ok && n.Op == token.SUB {
+					addLiteral(d, lit, true)
+					return false
+				}
+			case *ast.BasicLit:
+				if !tags[n] {
+					addLiteral(d, n, false)
+				}
+			}
+			return true
+		})
+	}
+	return d, nil
+}
+
+// addLiteral adds a literal to the dictionary, optionally negated.
+func addLiteral(d *dictionary, lit *ast.BasicLit, negate bool) {
+	switch lit.Kind {
+	case token.STRING, token.INT, token.CHAR:
+		val := constant.MakeFromLiteral(lit.Value, lit.Kind, 0)
+		if val.Kind() == constant.Unknown {
+			return
+		}
+		if lit.Kind == token.CHAR {
+			val = constant.ToInt(val)
+		}
+		if negate {
+			val = constant.UnaryOp(token.SUB, val, 0)
+		}
+		d.add(val)
+	}
+}
+
+// emitDictionary emits a package-level fuzzer.Dictionary variable.
+func emitDictionary(emit emitFunc, d *dictionary, varName string) {
+	emit("// %s contains literals and constants from the target package,\n", varName)
+	emit("// which fz.Fill uses to help reach comparisons against specific values.\n")
+	emit("var %s = fuzzer.Dictionary{\n", varName)
+	if len(d.strings) > 0 {
+		emit("\tStrings: []string{\n")
+		for _, s := range d.strings {
+			emit("\t\t%s,\n", strconv.Quote(s))
+		}
+		emit("\t},\n")
+	}
+	if len(d.ints) > 0 {
+		emit("\tInts: []int64{\n")
+		for _, i := range d.ints {
+			emit("\t\t%d,\n", i)
+		}
+		emit("\t},\n")
+	}
+	emit("}\n\n")
+}
+
+// newFuzzerCall returns the expression used in a wrapper to create a fuzzer.Fuzzer,
+// which uses the dictionary if we have one, in which case it also sets o.dictUsed.
+func (o wrapperOptions) newFuzzerCall(varName string) string {
+	if o.dictionary.empty() {
+		return "fuzzer.NewFuzzer(data)"
+	}
+	if o.dictUsed != nil {
+		*o.dictUsed = true
+	}
+	return "fuzzer.NewFuzzer(data, fuzzer.WithDictionary(" + varName + "))"
+}
@@ -0,0 +1,515 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/thepudds/fzgen/gen/internal/mod"
+)
+
+// diffPkg is the reference package for differential wrappers, along with
+// the name used to refer to it in the generated file.
+type diffPkg struct {
+	pkg  *types.Package
+	name string // package name, or an import alias if it collides with the target package's name
+}
+
+// emitDiffWrappers emits differential wrappers that call the functions in pkgFuncs along with
+// the functions of the same name and signature in the reference package, passing identical arguments
+// and panicking if the results differ. If chain is set, it instead emits a chain for each type that
+// has a matching constructor in both packages, with the steps being the methods with the same name
+// and signature in both packages, which are executed against both via fz.ChainDiff.
+func emitDiffWrappers(pkgPath string, pkgFuncs *pkg, refPkg *types.Package, wrapperPkgName string, chain bool, options wrapperOptions) ([]byte, error) {
+	if len(pkgFuncs.functions) == 0 {
+		return nil, fmt.Errorf("%w: 0 matching functions", ErrNoFunctionsMatch)
+	}
+	localPkg := pkgFuncs.functions[0].TypesFunc.Pkg()
+	ref := diffPkg{pkg: refPkg, name: refPkg.Name()}
+	if ref.name == localPkg.Name() {
+		ref.name += "ref"
+	}
+
+	// prepare the output
+	buf := new(bytes.Buffer)
+	var w io.Writer = buf
+	emit := func(format string, args ...interface{}) {
+		fmt.Fprintf(w, format, args...)
+	}
+
+	// emit the intro material
+	if options.buildConstraint != "" {
+		emit("%s\n\n", options.buildConstraint)
+	}
+	emit("package %s\n\n", wrapperPkgName)
+	emit("%s", options.topComment)
+	emit("import (\n")
+	emit("\t\"testing\"\n")
+	if options.qualifyAll {
+		emit("\t\"%s\"\n", pkgPath)
+	}
+	if ref.name != refPkg.Name() {
+		emit("\t%s \"%s\"\n", ref.name, refPkg.Path())
+	} else {
+		emit("\t\"%s\"\n", refPkg.Path())
+	}
+	emit("\t\"github.com/thepudds/fzgen/fuzzer\"\n")
+	emit(")\n\n")
+
+	// Track whether any wrapper uses our dictionary.
+	var dictUsed bool
+	options.dictUsed = &dictUsed
+
+	// put our functions we want to wrap into a deterministic order,
+	// sorting a copy so that we don't reorder our caller's slice.
+	sorted := *pkgFuncs
+	sorted.functions = append([]mod.Func(nil), pkgFuncs.functions...)
+	sort.Slice(sorted.functions, func(i, j int) bool {
+		return sorted.functions[i].TypesFunc.String() < sorted.functions[j].TypesFunc.String()
+	})
+	pkgFuncs = &sorted
+
+	var err error
+	if chain {
+		err = emitDiffChainWrappers(emit, pkgFuncs, ref, options)
+	} else {
+		err = emitDiffFuncWrappers(emit, pkgFuncs, ref, options)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Emit our dictionary at the end if any of our wrappers use fz.Fill.
+	if dictUsed {
+		emitDictionary(emit, options.dictionary, diffDictVarName)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// diffMatch returns the package-level function in the reference package with the same name
+// and an identical signature as f, or nil if there is none. Generic functions are not matched.
+func diffMatch(f *types.Func, ref diffPkg) *types.Func {
+	refFunc, ok := ref.pkg.Scope().Lookup(f.Name()).(*types.Func)
+	if !ok || !refFunc.Exported() {
+		return nil
+	}
+	sig, refSig := f.Type().(*types.Signature), refFunc.Type().(*types.Signature)
+	if sig.TypeParams().Len() > 0 || refSig.TypeParams().Len() > 0 || !types.Identical(sig, refSig) {
+		return nil
+	}
+	return refFunc
+}
+
+// emitDiffFuncWrappers emits a differential wrapper for each package-level function
+// that has a matching function in the reference package. It only returns an error if all fail.
+func emitDiffFuncWrappers(emit emitFunc, pkgFuncs *pkg, ref diffPkg, options wrapperOptions) error {
+	var firstErr error
+	var success bool
+	for _, function := range pkgFuncs.functions {
+		f := function.TypesFunc
+		sig := f.Type().(*types.Signature)
+		switch {
+		case sig.Recv() != nil:
+			options.skip(function, "methods are only compared with -chain")
+			continue
+		case sig.Results().Len() == 0:
+			options.skip(function, "no results to compare")
+			continue
+		}
+		refFunc := diffMatch(f, ref)
+		if refFunc == nil {
+			options.skip(function, "no function with the same name and signature in the reference package")
+			continue
+		}
+		err := emitDiffFuncWrapper(emit, function, ref, options)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if err == nil {
+			success = true
+		}
+	}
+	if !success {
+		if firstErr == nil {
+			firstErr = fmt.Errorf("%w: no functions with the same name and signature in %s", ErrNoFunctionsMatch, ref.pkg.Path())
+		}
+		return firstErr
+	}
+	return nil
+}
+
+// diffParams describes the parameters of a differential wrapper. The reference implementation
+// is passed separate copies of any parameters that could share memory, such as slices, so that it
+// is not affected by any modifications made by the target implementation.
+type diffParams struct {
+	vars     []*types.Var
+	names    []string // names of the parameters passed to the target implementation
+	refNames []string // names of the parameters passed to the reference implementation
+	native   bool     // whether the parameters are parameters of the fuzz function, rather than filled via fz.Fill
+}
+
+// newDiffParams returns the diffParams for vars, or an error if they are not supported.
+// If forceFill is set, the parameters are filled via fz.Fill even if natively supported by cmd/go.
+func newDiffParams(vars []*types.Var, localPkg *types.Package, forceFill bool) (diffParams, error) {
+	p := diffParams{vars: vars}
+	support, unsupported := checkParamSupport(vars)
+	if support == noSupport {
+		return p, fmt.Errorf("%w: %s", ErrUnsupportedParams, unsupported)
+	}
+	p.native = support == nativeSupport && !forceFill && len(vars) > 0
+	var alias bool
+	for _, v := range vars {
+		alias = alias || mayAlias(v.Type())
+	}
+	for i, v := range vars {
+		name := avoidCollision(v, i, localPkg, vars)
+		p.names = append(p.names, name)
+		if alias && (!p.native || mayAlias(v.Type())) {
+			// With fz.Fill, we fill all of the reference's parameters in order to get identical values.
+			name += "Ref"
+		}
+		p.refNames = append(p.refNames, name)
+	}
+	return p, nil
+}
+
+// emitFill emits the declarations for our parameters, including filling them via fz.Fill if needed,
+// and reports whether it emitted anything.
+// The reference's copies are filled from a second fuzzer.Fuzzer using the same data, which results
+// in identical values, or copied for a natively supported []byte. The result will be similar to:
+//    var m map[string]int
+//    fz := fuzzer.NewFuzzer(data)
+//    fz.Fill(&m)
+//    var mRef map[string]int
+//    fzRef := fuzzer.NewFuzzer(data)
+//    fzRef.Fill(&mRef)
+func (p diffParams) emitFill(emit emitFunc, localPkg *types.Package, options wrapperOptions) bool {
+	defaultQualifier, _ := qualifiers(localPkg, options.qualifyAll)
+	fill := func(fzName string, names []string) {
+		for i, v := range p.vars {
+			emit("\t\tvar %s %s\n", names[i], types.TypeString(v.Type(), defaultQualifier))
+		}
+		emit("\t\t%s := %s\n", fzName, options.newFuzzerCall(diffDictVarName))
+		emit("\t\t%s.Fill(&%s)\n", fzName, strings.Join(names, ", &"))
+	}
+	emitted := !p.native
+	if !p.native {
+		fill("fz", p.names)
+	}
+	for i := range p.vars {
+		if p.refNames[i] == p.names[i] {
+			continue
+		}
+		emitted = true
+		if p.native {
+			emit("\t%s := append([]byte(nil), %s...)\n", p.refNames[i], p.names[i])
+		} else {
+			fill("fzRef", p.refNames)
+			break
+		}
+	}
+	return emitted
+}
+
+// argList returns the argument list for a call, which ends with an ellipsis if variadic is set.
+func argList(names []string, variadic bool) string {
+	s := strings.Join(names, ", ")
+	if variadic {
+		s += "..."
+	}
+	return s
+}
+
+// mayAlias reports whether a value of type t could share memory with a copy of the value,
+// such as a slice or a struct with a pointer field.
+func mayAlias(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return false
+	case *types.Array:
+		return mayAlias(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if mayAlias(u.Field(i).Type()) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// emitDiffFuncWrapper emits a differential wrapper for a package-level function,
+// which calls both implementations with identical arguments and passes the results
+// to fuzzer.CheckDiff, which panics if they differ.
+func emitDiffFuncWrapper(emit emitFunc, function mod.Func, ref diffPkg, options wrapperOptions) error {
+	f := function.TypesFunc
+	sig := f.Type().(*types.Signature)
+	localPkg := f.Pkg()
+	defaultQualifier, _ := qualifiers(localPkg, options.qualifyAll)
+	wrapperName := fmt.Sprintf("Fuzz_Diff_%s", f.Name())
+
+	p, err := newDiffParams(params(f), localPkg, false)
+	if err != nil {
+		emit("// skipping %s because parameters include func, chan, or unsupported interface: %v\n\n", wrapperName, err)
+		options.skip(function, "parameters include func, chan, or unsupported interface")
+		return fmt.Errorf("%s: %w", f.Name(), err)
+	}
+	r := newRecord(function, StatusDiff)
+	r.Wrapper = wrapperName
+	options.record(r)
+
+	emit("func %s(f *testing.F) {\n", wrapperName)
+	if p.native {
+		var decls []string
+		for i, v := range p.vars {
+			decls = append(decls, fmt.Sprintf("%s %s", p.names[i], types.TypeString(v.Type(), defaultQualifier)))
+		}
+		emit("\tf.Fuzz(func(t *testing.T, %s) {\n", strings.Join(decls, ", "))
+	} else {
+		emit("\tf.Fuzz(func(t *testing.T, data []byte) {\n")
+	}
+	filled := len(p.vars) > 0 && p.emitFill(emit, localPkg, options)
+	emitNilChecks(emit, p.vars, localPkg)
+	if filled {
+		emit("\n")
+	}
+
+	// Call both implementations.
+	var results, refResults []string
+	for i := 0; i < sig.Results().Len(); i++ {
+		results = append(results, fmt.Sprintf("result%d", i+1))
+		refResults = append(refResults, fmt.Sprintf("ref%d", i+1))
+	}
+	name := f.Name()
+	if options.qualifyAll {
+		name = localPkg.Name() + "." + name
+	}
+	emit("\t%s := %s(%s)\n", strings.Join(results, ", "), name, argList(p.names, sig.Variadic()))
+	emit("\t%s := %s.%s(%s)\n", strings.Join(refResults, ", "), ref.name, f.Name(), argList(p.refNames, sig.Variadic()))
+	emit("\tfuzzer.CheckDiff(%q, []interface{}{%s}, []interface{}{%s}, []interface{}{%s})\n",
+		f.Name(), strings.Join(p.names, ", "), strings.Join(results, ", "), strings.Join(refResults, ", "))
+	emit("\t})\n")
+	emit("}\n\n")
+	return nil
+}
+
+// diffChain is a type with a matching constructor and methods in both the target and reference packages.
+type diffChain struct {
+	named   *types.Named
+	refN    *types.Named
+	ctor    mod.Func
+	methods []mod.Func
+}
+
+// emitDiffChainWrappers emits a differential chain wrapper for each type in the target package
+// that has a matching type in the reference package. It only returns an error if all fail.
+func emitDiffChainWrappers(emit emitFunc, pkgFuncs *pkg, ref diffPkg, options wrapperOptions) error {
+	chains := make(map[string]*diffChain)
+	var recvTypes []string
+	for _, function := range pkgFuncs.functions {
+		recvN := receiver(function.TypesFunc)
+		if recvN == nil {
+			options.skip(function, "not a method")
+			continue
+		}
+		if recvN.TypeParams().Len() > 0 {
+			options.skip(function, "methods on generic types are not compared")
+			continue
+		}
+		recvType := recvN.Obj().Name()
+		c := chains[recvType]
+		if c == nil {
+			c = &diffChain{named: recvN}
+			chains[recvType] = c
+			recvTypes = append(recvTypes, recvType)
+		}
+		c.methods = append(c.methods, function)
+	}
+	sort.Strings(recvTypes)
+
+	var firstErr error
+	var success bool
+	for _, recvType := range recvTypes {
+		c := chains[recvType]
+		err := c.match(pkgFuncs.constructors, ref)
+		if err != nil {
+			for _, function := range c.methods {
+				options.skip(function, err.Error())
+			}
+		} else {
+			// emitDiffChainWrapper records the outcome for each method.
+			err = emitDiffChainWrapper(emit, c, ref, options)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if err == nil {
+			success = true
+		}
+	}
+	if !success {
+		if firstErr == nil {
+			firstErr = ErrNoMethodsMatch
+		}
+		return firstErr
+	}
+	return nil
+}
+
+// match finds the type of the same name in the reference package, along with the first of the
+// possibleConstructors for c.named that has a match in the reference package.
+func (c *diffChain) match(possibleConstructors []mod.Func, ref diffPkg) error {
+	tn, ok := ref.pkg.Scope().Lookup(c.named.Obj().Name()).(*types.TypeName)
+	if !ok {
+		return fmt.Errorf("%w: no type %s in the reference package", ErrNoMethodsMatch, c.named.Obj().Name())
+	}
+	c.refN, ok = tn.Type().(*types.Named)
+	if !ok || c.refN.TypeParams().Len() > 0 {
+		return fmt.Errorf("%w: no type %s in the reference package", ErrNoMethodsMatch, c.named.Obj().Name())
+	}
+	for _, ctor := range possibleConstructors {
+		ctorResultN, returnsErr := constructorResult(ctor.TypesFunc)
+		if ctorResultN == nil || !types.Identical(ctorResultN, c.named) {
+			continue
+		}
+		if support, _ := checkParamSupport(params(ctor.TypesFunc)); support == noSupport {
+			continue
+		}
+		refCtor, ok := ref.pkg.Scope().Lookup(ctor.TypesFunc.Name()).(*types.Func)
+		if !ok {
+			continue
+		}
+		refResultN, refReturnsErr := constructorResult(refCtor)
+		if refResultN == nil || !types.Identical(refResultN, c.refN) || refReturnsErr != returnsErr {
+			continue
+		}
+		sig, refSig := ctor.TypesFunc.Type().(*types.Signature), refCtor.Type().(*types.Signature)
+		if refSig.TypeParams().Len() > 0 || !types.Identical(sig.Params(), refSig.Params()) || sig.Variadic() != refSig.Variadic() {
+			continue
+		}
+		c.ctor = ctor
+		return nil
+	}
+	return fmt.Errorf("%w in both packages for %s", ErrNoConstructorsMatch, c.named.Obj().Name())
+}
+
+// emitDiffChainWrapper emits a chain wrapper that creates a target from each package via
+// the matching constructors, and then executes identical steps against both via fz.ChainDiff.
+func emitDiffChainWrapper(emit emitFunc, c *diffChain, ref diffPkg, options wrapperOptions) error {
+	ctor := c.ctor.TypesFunc
+	localPkg := ctor.Pkg()
+	defaultQualifier, _ := qualifiers(localPkg, options.qualifyAll)
+	wrapperName := fmt.Sprintf("Fuzz_Diff_%s_Chain", ctor.Name())
+	_, returnsErr := constructorResult(ctor)
+
+	// Find our steps, which are the methods with an identical signature in both packages.
+	var steps []*types.Func
+	for _, function := range c.methods {
+		f := function.TypesFunc
+		refSig := method(c.refN, f.Name())
+		if refSig == nil || !types.Identical(f.Type(), refSig) {
+			options.skip(function, "no method with the same name and signature in the reference package")
+			continue
+		}
+		if support, unsupported := checkParamSupport(params(f)); support == noSupport {
+			options.skipUnsupported(function, "", unsupported)
+			continue
+		}
+		r := newRecord(function, StatusDiff)
+		r.Wrapper = wrapperName
+		r.Constructor = ctor.Name()
+		options.record(r)
+		steps = append(steps, f)
+	}
+	if len(steps) == 0 {
+		return ErrNoSteps
+	}
+
+	// Create our targets. The result will be similar to:
+	//    var size int
+	//    fz := fuzzer.NewFuzzer(data)
+	//    fz.Fill(&size)
+	//
+	//    target, err := NewBuffer(size)
+	//    ref, refErr := bufferref.NewBuffer(size)
+	//    fuzzer.CheckDiff("NewBuffer", []interface{}{size}, []interface{}{err}, []interface{}{refErr})
+	//    if err != nil {
+	//      return
+	//    }
+	p, err := newDiffParams(params(ctor), localPkg, true)
+	if err != nil {
+		return err
+	}
+	emit("func %s(f *testing.F) {\n", wrapperName)
+	emit("\tf.Fuzz(func(t *testing.T, data []byte) {\n")
+	if len(p.vars) > 0 {
+		p.emitFill(emit, localPkg, options)
+	} else {
+		emit("\t\tfz := %s\n", options.newFuzzerCall(diffDictVarName))
+	}
+	emitNilChecks(emit, p.vars, localPkg)
+	emit("\n")
+	name := ctor.Name()
+	if options.qualifyAll {
+		name = localPkg.Name() + "." + name
+	}
+	sig := ctor.Type().(*types.Signature)
+	if returnsErr {
+		emit("\ttarget, err := %s(%s)\n", name, argList(p.names, sig.Variadic()))
+		emit("\tref, refErr := %s.%s(%s)\n", ref.name, ctor.Name(), argList(p.refNames, sig.Variadic()))
+		emit("\tfuzzer.CheckDiff(%q, []interface{}{%s}, []interface{}{err}, []interface{}{refErr})\n",
+			ctor.Name(), strings.Join(p.names, ", "))
+		emit("\tif err != nil {\n")
+		emit("\t\treturn\n")
+		emit("\t}\n")
+	} else {
+		emit("\ttarget := %s(%s)\n", name, argList(p.names, sig.Variadic()))
+		emit("\tref := %s.%s(%s)\n", ref.name, ctor.Name(), argList(p.refNames, sig.Variadic()))
+	}
+	emit("\n")
+
+	emitDiffSteps := func(varName string, stepsVar string) {
+		emit("\t%s := []fuzzer.Step{\n", stepsVar)
+		for _, f := range steps {
+			sig := f.Type().(*types.Signature)
+			var decls, names, results []string
+			for i, v := range params(f) {
+				name := avoidCollision(v, i, localPkg, params(f))
+				decls = append(decls, fmt.Sprintf("%s %s", name, types.TypeString(v.Type(), defaultQualifier)))
+				names = append(names, name)
+			}
+			for i := 0; i < sig.Results().Len(); i++ {
+				results = append(results, types.TypeString(sig.Results().At(i).Type(), defaultQualifier))
+			}
+			emit("\t{\n")
+			emit("\t\tName: \"Fuzz_%s_%s\",\n", c.named.Obj().Name(), f.Name())
+			resultList := strings.Join(results, ", ")
+			if len(results) > 1 {
+				resultList = "(" + resultList + ")"
+			}
+			emit("\t\tFunc: func(%s) %s {\n", strings.Join(decls, ", "), resultList)
+			if len(results) > 0 {
+				emit("\t\t\treturn ")
+			}
+			emit("\t%s.%s(%s)\n", varName, f.Name(), argList(names, sig.Variadic()))
+			emit("\t\t},\n")
+			emit("\t},\n")
+		}
+		emit("\t}\n")
+	}
+	emitDiffSteps("target", "steps")
+	emitDiffSteps("ref", "refSteps")
+
+	emit("\n\t// Execute the same chain of steps against target and ref, with the count, sequence and arguments\n")
+	emit("\t// controlled by fz.ChainDiff, which panics if the results of any step differ.\n")
+	emit("\tfz.ChainDiff(steps, refSteps)\n")
+	emit("\t})\n")
+	emit("}\n\n")
+	return nil
+}
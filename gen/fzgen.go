@@ -1,22 +1,24 @@
 // Package gen automatically generates fuzz functions, and is the main entry point
-// for the fzgen command.
+// for the fzgen command. Generate allows using fzgen from other programs,
+// such as go:generate drivers or other tooling, without running the fzgen binary.
 //
 // See the project README for additional information:
 //     https://github.com/thepudds/fzgen
 package gen
 
 import (
-	"errors"
+	"bytes"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strconv"
 	"strings"
 
-	"golang.org/x/tools/imports"
+	"github.com/pkg/diff"
 )
 
 // one way to test this is against the stdlib (here, this just tests that fzgen generates and the result compiles successfully):
@@ -30,7 +32,9 @@ import (
 // Usage contains short usage information.
 var Usage = `
 Usage:
-	fzgen [-chain] [-parallel] [-ctor=<target-constructor-regexp>] [-unexported] [package]
+	fzgen [-chain] [-parallel] [-ctor=<target-constructor-regexp>] [-unexported] [-config=<file>] [-diff=<reference-package>]
+	      [-typeargs=<type-list>] [-merge] [-check] [-tags=<tag-list>] [-seeds=false] [-dict=false]
+	      [-report=<file>] [-ctorfallback=none|zero|fill] [-invariants=<method-list>] [packages]
 	
 Running fzgen without any arguments targets the package in the current directory.
 
@@ -42,14 +46,105 @@ module or listed as dependency of the current module (e.g., via 'go get example.
 The resulting wrapper functions will all start with 'Fuzz', and are candidates 
 for use with fuzzing via Go 1.18 cmd/go (e.g., 'gotip test -fuzz=.').
 
-fzgen supports a package pattern as the last argument. If multiple packages match,
-the generated files will be placed in each target package's directory. Otherwise,
-when there is only a single target package, the generated file will be placed
-in the current working directory.
+fzgen supports one or more package patterns as the last arguments. If multiple
+packages match, the generated files will be placed in each target package's directory.
+Otherwise, when there is only a single target package, the generated file will be
+placed in the current working directory.
 
 Test functions and any function that already starts with 'Fuzz' are skipped,
 as are functions that have unsupported parameters such as a channel.
 
+Generic functions and methods on generic types are instantiated with concrete
+type arguments, with one wrapper emitted per instantiation. A type parameter
+constrained by a union such as '~int | ~float64' uses the types from the union,
+and otherwise uses the types listed in the -typeargs flag. With -chain, each
+instantiation of a generic type gets its own chain, using an instantiated
+generic constructor if available.
+
+Without -chain, fzgen also emits a roundtrip wrapper for each pair of package-level
+functions where one undoes the other, such as 'Quote' and 'Unquote', or 'FormatPoint'
+and 'ParsePoint'. The pairs are recognized by name (Encode/Decode, Marshal/Unmarshal,
+Compress/Decompress, Quote/Unquote, Format/Parse, and Escape/Unescape) and by signature.
+The wrapper panics if decoding fails or does not return the original value.
+With -chain, the target type is instead checked with any roundtrip methods it
+implements, such as MarshalText and UnmarshalText, or String along with a package-level Parse.
+
+With -chain, the steps of a chain are the methods of the target type, along with
+any package-level functions that take the target type as a parameter, such as
+'func Merge(dst, src *Bitmap)', where the chain's target is passed for the first
+such parameter. Constructors and other package-level functions that return the
+target type are also steps, and their return values can be used as arguments
+to later steps, such as the 'other' in 'func (b *Bitmap) And(other *Bitmap)'.
+Steps return any error from the wrapped function, and by default the chain
+continues after an error. The generated fz.Chain call can be edited to pass
+fuzzer.ChainStopOnError to stop the sequence after an error, or
+fuzzer.ChainFailOnError with step names to treat their errors as failures.
+
+With -chain, each target type needs a constructor in the same package that
+matches the -ctor regexp, such as 'func NewBuffer() *Buffer'. If several
+constructors match, the fuzzer chooses which one creates the target for each
+input, so that each way of constructing the target is exercised. Types without
+a matching constructor are skipped by default. Types whose zero value is
+usable can instead be chain fuzzed by setting -ctorfallback=zero, which
+declares the target as a zero value, or -ctorfallback=fill, which also
+populates the target's exported fields via fz.Fill.
+
+With -chain, any methods of the target named by the -invariants flag are called
+after each step to check the target's consistency, which by default are Validate,
+CheckInvariants, and Len. These can return an error, a bool that is false on failure,
+an int such as a length that must not be negative, or nothing, in which case they
+should panic on failure. A failed check reports the step that was just executed,
+which catches corruption at the step that caused it.
+
+The -diff flag generates differential wrappers that compare the target package
+against a reference package with the same API, such as a fast path and a simpler
+reference implementation, or a new version and an old one. Each package-level
+function with the same name and signature in both packages gets a wrapper that
+passes identical arguments to both and panics with both results if they differ.
+Errors are compared only by whether they are nil. With -chain, each type that has
+a constructor with the same name and parameters in both packages gets a chain whose
+steps are the methods with the same name and signature in both packages, and each
+step is executed against both targets via fz.ChainDiff. The output defaults to
+autofuzzdiff_test.go, or autofuzzdiffchain_test.go with -chain.
+
+A JSON config file can be supplied via -config to set the func and ctor regexps,
+functions to exclude, chain and parallel modes, output file names, and value hints
+for parameters on a per-package basis, which allows regenerating all the wrappers
+for a module with one command such as 'fzgen -config=fzgen.json ./...'.
+Explicitly set flags override the config file.
+
+The -merge flag regenerates wrappers into an existing output file while
+preserving hand edits. Any wrapper whose doc comment contains a //fzgen:keep
+line is left as is, as are other declarations such as helper functions.
+Other wrappers are regenerated, with a warning for any that differ from the
+regenerated version, new wrappers are added, and wrappers for functions that
+no longer exist are removed.
+
+The -check flag runs the same generation steps but does not write any files.
+Instead, it reports any differences with the existing output files, and exits
+with a non-zero status if any file is out of date, which can be used in CI.
+
+By default, any calls in the target package's existing tests and examples to
+the wrapped functions or their constructors that use only constant arguments,
+such as 'Parse("1.2.3")', are used as seed corpus entries via f.Add. This
+can be disabled with -seeds=false.
+
+Wrappers that use fz.Fill or fz.Chain also use a dictionary of string and
+integer literals and constants from the target package, which can help the
+fuzzer reach comparisons against specific values. This can be disabled
+with -dict=false.
+
+The -tags flag sets build tags used when loading the target packages, and
+GOOS and GOARCH are respected if set in the environment. The generated files
+then start with a matching //go:build line if there are tags or if GOOS or
+GOARCH differ from the host's.
+
+The -report flag writes a JSON record for each candidate function to a file,
+one per line. Each record includes the package, receiver, function name, and
+wrapper name, along with a status of native, fill, chain, or skipped. Skipped
+functions include the reason, such as the unsupported parameter type, and
+wrapped methods include any constructor used to create the receiver.
+
 `
 
 var (
@@ -57,6 +152,8 @@ var (
 	debugForceNoTopComment bool
 )
 
+// FzgenMain runs the fzgen command, and returns the exit code.
+// It is a command line interface over Generate.
 func FzgenMain() int {
 	// handle flags
 	flag.Usage = func() {
@@ -69,180 +166,228 @@ func FzgenMain() int {
 	parallelFlag := flag.Bool("parallel", false, "indicates an emitted chain can be run in parallel. requires -chain")
 	outFileFlag := flag.String("o", "autofuzz_test.go", "output file name. defaults to autofuzz_test.go or autofuzzchain_test.go")
 	constructorPatternFlag := flag.String("ctor", ".", "regexp to use if searching for constructors to automatically use.")
+	ctorFallbackFlag := flag.String("ctorfallback", ctorFallbackNone, "how -chain creates the target for a type without a matching constructor: "+
+		"none to skip the type, zero for a zero value, or fill for a value populated via fz.Fill.")
+	invariantsFlag := flag.String("invariants", defaultInvariants, "comma-separated list of methods that -chain calls after each step "+
+		"to check the target's consistency, or none to disable.")
 
 	// Less commonly used:
 	funcPatternFlag := flag.String("func", ".", "function regex, defaults to matching all candidate functions")
 	unexportedFlag := flag.Bool("unexported", false, "emit wrappers for unexported functions in addition to exported functions")
 	constructorFlag := flag.Bool("ctorinject", true, "automatically insert constructors when wrapping a method call "+
 		"if a suitable constructor can be found in the same package.")
+	seedsFlag := flag.Bool("seeds", true, "emit seed corpus entries via f.Add using constant arguments from calls "+
+		"to the target functions in the package's existing tests and examples.")
+	dictFlag := flag.Bool("dict", true, "emit a dictionary of string and integer literals and constants from the target package, "+
+		"which wrappers using fz.Fill or fz.Chain use to help reach comparisons against specific values.")
+	typeArgsFlag := flag.String("typeargs", defaultTypeArgs, "comma-separated list of types used to instantiate type parameters "+
+		"of generic functions and types when the constraint does not list specific types.")
+	tagsFlag := flag.String("tags", "", "comma-separated list of build tags to use when loading packages. "+
+		"the generated files will have a matching //go:build line, which also includes GOOS and GOARCH if set for cross compilation.")
+	checkFlag := flag.Bool("check", false, "check that existing output files are up to date without writing any files. "+
+		"prints a diff and exits with a non-zero status if any file would change.")
+	mergeFlag := flag.Bool("merge", false, "merge into an existing output file, preserving any wrappers marked with a //fzgen:keep comment "+
+		"as well as any other declarations, and removing other wrappers for functions that no longer exist.")
+	configFlag := flag.String("config", "", "JSON config file with settings for each target package, such as fzgen.json. "+
+		"Explicitly set flags override the config file.")
+	diffFlag := flag.String("diff", "", "import path of a reference package with the same API as the target package. "+
+		"emits differential wrappers that pass identical arguments to both packages and panic if the results differ.")
+	reportFlag := flag.String("report", "", "write a JSON record for each candidate function to the named file, one per line, "+
+		"including whether it was wrapped natively, via fz.Fill, or as a chain step, or why it was skipped.")
 
 	flag.Parse()
 
-	var pkgPattern string
-	switch {
-	case flag.NArg() > 1:
-		fmt.Println("fzgen: only one package pattern argument is allowed, and it must match only one package")
-		flag.Usage()
-		return 2
-	case flag.NArg() == 1:
-		pkgPattern = flag.Arg(0)
-	default:
-		pkgPattern = "."
+	pkgPatterns := flag.Args()
+	if len(pkgPatterns) == 0 {
+		pkgPatterns = []string{"."}
 	}
 
-	if *parallelFlag && !*chainFlag {
+	// Track which flags were explicitly set, which take precedence over any config file.
+	var explicit genSettings
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "chain":
+			explicit.Chain = chainFlag
+		case "parallel":
+			explicit.Parallel = parallelFlag
+		case "o":
+			explicit.Output = *outFileFlag
+		case "ctor":
+			explicit.Ctor = *constructorPatternFlag
+		case "func":
+			explicit.Func = *funcPatternFlag
+		case "unexported":
+			explicit.Unexported = unexportedFlag
+		case "ctorinject":
+			explicit.CtorInject = constructorFlag
+		case "typeargs":
+			explicit.TypeArgs = *typeArgsFlag
+		case "seeds":
+			explicit.Seeds = seedsFlag
+		case "dict":
+			explicit.Dict = dictFlag
+		case "ctorfallback":
+			explicit.CtorFallback = *ctorFallbackFlag
+		case "diff":
+			explicit.Diff = *diffFlag
+		case "invariants":
+			explicit.Invariants = *invariantsFlag
+		}
+	})
+	flagSettings := defaultSettings().merge(explicit)
+
+	if *configFlag == "" && *flagSettings.Parallel && !*flagSettings.Chain {
 		fmt.Fprint(os.Stderr, "fzgen: -parallel flag requires -chain\n")
 		return 2
 	}
-
-	if *chainFlag && *outFileFlag == "autofuzz_test.go" {
-		*outFileFlag = "autofuzzchain_test.go"
-	}
-
-	// Search for functions in the requested packages that match the supplied func and ctor regex.
-	options := flagExcludeFuzzPrefix | flagMultiMatch
-	if !*unexportedFlag {
-		options |= flagRequireExported
-	}
-	pkgs, err := findFuncsGrouped(pkgPattern, *funcPatternFlag, *constructorPatternFlag, options)
-	if err != nil {
+	if err := checkCtorFallback(flagSettings.CtorFallback); err != nil {
 		fmt.Fprintf(os.Stderr, "fzgen: %v\n", err)
-		return 1
+		return 2
 	}
-
-	// Check if we are looking at one package vs. multiple.
-	if len(pkgs) > 1 && hasPath(*outFileFlag) {
-		fmt.Fprint(os.Stderr, "fzgen:-o can only specify a file name and not a path when the package pattern matches multiple packages\n")
+	tags := splitTags(*tagsFlag)
+	if _, err := buildConstraint("", tags); err != nil {
+		fmt.Fprintf(os.Stderr, "fzgen: %v\n", err)
 		return 2
 	}
 
 	wd, err := os.Getwd()
 	if err != nil {
-		fail(err)
+		fmt.Fprintf(os.Stderr, "fzgen: %v\n", err)
+		return 1
 	}
 
-	var topComment string
+	opts := Options{
+		Patterns: pkgPatterns,
+		Dir:      wd,
+		Config:   *configFlag,
+		Tags:     tags,
+		Merge:    *mergeFlag,
+
+		ForceLocal: debugForceLocal,
+	}
 	if !debugForceNoTopComment {
-		args := os.Args[1 : len(os.Args)-flag.NArg()]
-		args = append(args, []string{pkgPattern}...)
-		topComment = fmt.Sprintf(topCommentTmpl, strings.Join(args, " "))
+		var args []string
+		flagArgs := os.Args[1 : len(os.Args)-flag.NArg()]
+		for i := 0; i < len(flagArgs); i++ {
+			// Omit -check, -merge, and -report so that checking, merging, or reporting produces
+			// the same top comment as the original generation.
+			arg := flagArgs[i]
+			name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+			switch {
+			case name == "check", name == "merge":
+				continue
+			case arg == "-report", arg == "--report":
+				i++
+				continue
+			case name == "report":
+				continue
+			}
+			args = append(args, arg)
+		}
+		args = append(args, pkgPatterns...)
+		opts.TopComment = fmt.Sprintf(topCommentTmpl, strings.Join(args, " "))
 	}
 
-	// Loop over our packages, and start our real work.
-	var generatedFiles int
-	for i := range pkgs {
-		if len(pkgs[i].functions) == 0 {
-			continue
+	// If requested, write a report record for each candidate function as JSON lines.
+	var reportErr error
+	if *reportFlag != "" {
+		report, err := os.Create(*reportFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fzgen: %v\n", err)
+			return 1
 		}
-
-		// Determine what output file we will create, and what package name we will use in it.
-		var wrapperPkgName string
-		outFile := *outFileFlag
-		targetPkgName := pkgs[i].functions[0].PkgName
-		switch {
-		case len(pkgs) > 1:
-			// Specifying multiple packages via a pattern creates an output file in each package's directory.
-			outFile = filepath.Join(pkgs[i].functions[0].PkgDir, outFile)
-			wrapperPkgName = targetPkgName
-		case len(pkgs) == 1:
-			// When the target is a single package overall, we default to placing the output file in the working directory,
-			// with the ability to set a more specific output file path via -o. For the working directory or some other user-supplied
-			// output location, there might already be a package there, so we need to look up that package name if it exists.
-			wrapperPkgName = outDirPkgName(outFile)
-			if wrapperPkgName == "" {
-				// We did not find a package name in our destination (e.g., might not have any .go files there),
-				// so make up a new package name.
-				wrapperPkgName = targetPkgName + "fuzz"
+		defer report.Close()
+		enc := json.NewEncoder(report)
+		opts.Report = func(r Record) {
+			if err := enc.Encode(r); err != nil && reportErr == nil {
+				reportErr = err
 			}
-		default:
-			panic("impossible")
 		}
+	}
 
-		// If the output file will end up in the target package dir, we set qualifyAll to false
-		// so that emitted references to types from the target package will not be qualified with the package prefix.
-		outDir, err := filepath.Abs(filepath.Dir(outFile))
-		if err != nil {
-			fail(err)
-		}
-		targetDir, err := filepath.Abs(pkgs[i].functions[0].PkgDir)
+	// Do the actual work of generating our wrappers.
+	files, skipped, err := generate(context.Background(), opts, explicit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fzgen: %v\n", err)
+		return 1
+	}
+	if reportErr != nil {
+		fmt.Fprintf(os.Stderr, "fzgen: failed to write report: %v\n", reportErr)
+		return 1
+	}
+
+	// Report any skipped packages. If we only targeted a single package, that is an error.
+	if len(files) == 0 && len(skipped) == 1 {
+		fmt.Fprintf(os.Stderr, "fzgen: %v\n", skipped[0].Err)
+		return 1
+	}
+	for _, sk := range skipped {
+		fmt.Printf("fzgen: skipping %s: %v\n", sk.PkgPath, sk.Err)
+	}
+
+	var staleFiles int
+	for _, file := range files {
+		rel, err := filepath.Rel(wd, file.Path)
 		if err != nil {
-			fail(err)
+			rel = file.Path
 		}
-		qualifyAll := targetDir != outDir
-
-		if debugForceLocal {
-			qualifyAll = false
+		for _, w := range file.Warnings {
+			fmt.Fprintf(os.Stderr, "fzgen: warning: %s: %s\n", rel, w)
 		}
-
-		wrapperOpts := wrapperOptions{
-			qualifyAll:         qualifyAll,
-			insertConstructors: *constructorFlag,
-			parallel:           *parallelFlag,
-			topComment:         topComment,
+		for _, name := range file.Stale {
+			fmt.Fprintf(os.Stderr, "fzgen: warning: %s: keeping %s marked %s, but it no longer matches a generated wrapper\n", rel, name, keepDirective)
 		}
-
-		// Do the actual work of emitting our wrappers.
-		var out []byte
-		if !*chainFlag {
-			out, err = emitIndependentWrappers(pkgs[i].pkgPath, pkgs[i], wrapperPkgName, wrapperOpts)
-		} else {
-			out, err = emitChainWrappers(pkgs[i].pkgPath, pkgs[i], wrapperPkgName, wrapperOpts)
+		for _, name := range file.Replaced {
+			fmt.Fprintf(os.Stderr, "fzgen: warning: %s: replacing %s, which differs from the generated wrapper. mark it %s to keep edits\n", rel, name, keepDirective)
 		}
-		// Handle certain common errors gracefully, including skipping & continuing if multiple target packages.
-		msgDest, msgPrefix := os.Stderr, "fzgen:"
-		if len(pkgs) > 1 {
-			msgDest, msgPrefix = os.Stdout, fmt.Sprintf("fzgen: skipping %s:", pkgs[i].pkgPath)
+		for _, name := range file.Removed {
+			fmt.Printf("fzgen: %s: removed %s\n", rel, name)
 		}
-		switch {
-		case errors.Is(err, errUnsupportedParams), errors.Is(err, errNoMethodsMatch), errors.Is(err, errNoSteps), errors.Is(err, errNoFunctionsMatch):
-			fmt.Fprintf(msgDest, "%s %v\n", msgPrefix, err)
-			if len(pkgs) > 1 {
-				continue
+
+		// If requested, compare against the existing file rather than writing the output.
+		if *checkFlag {
+			existing, err := ioutil.ReadFile(file.Path)
+			if err != nil && !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "fzgen: %v\n", err)
+				return 1
 			}
-			return 1
-		case errors.Is(err, errNoConstructorsMatch):
-			fmt.Fprintf(msgDest, "%s %v for -ctor pattern %q\n", msgPrefix, err, *constructorPatternFlag)
-			if len(pkgs) > 1 {
-				continue
+			if !bytes.Equal(existing, file.Content) {
+				staleFiles++
+				err = diff.Text(rel, rel+" (regenerated)", existing, file.Content, os.Stdout)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "fzgen: %v\n", err)
+					return 1
+				}
+				fmt.Fprintf(os.Stderr, "fzgen: %s is out of date\n", rel)
 			}
-			return 1
-		}
-		if err != nil {
-			fail(err)
+			continue
 		}
-		generatedFiles++
 
-		// Fix up any needed imports.
-		var adjusted []byte
-		abs, err := filepath.Abs(outFile)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "fzgen: warning: continuing after failing to find abs path:", err)
-			abs = outFile
-		}
-		adjusted, err = imports.Process(abs, out, nil)
+		// Write the output.
+		err = ioutil.WriteFile(file.Path, file.Content, 0o644)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, "fzgen: warning: continuing after failing to automatically adjust imports:", err)
-			adjusted = out
+			fmt.Fprintf(os.Stderr, "fzgen: %v\n", err)
+			return 1
 		}
 
-		// Write the output.
-		err = ioutil.WriteFile(outFile, adjusted, 0o644)
-		if err != nil {
-			fail(err)
+		if file.Merged {
+			fmt.Println("fzgen: updated", rel)
+		} else {
+			fmt.Println("fzgen: created", rel)
 		}
+	}
 
-		rel := outFile
-		if hasPath(outFile) {
-			rel, err = filepath.Rel(wd, outFile)
-			if err != nil {
-				rel = outFile
-			}
+	if *checkFlag {
+		if staleFiles > 0 {
+			fmt.Fprintf(os.Stderr, "fzgen: %d of %d generated files are out of date. rerun fzgen without -check to update.\n", staleFiles, len(files))
+			return 1
 		}
-		fmt.Println("fzgen: created", rel)
+		fmt.Printf("fzgen: %d generated files are up to date\n", len(files))
+		return 0
 	}
 
-	if generatedFiles > 1 {
-		fmt.Printf("fzgen: created %d files\n", generatedFiles)
+	if len(files) > 1 {
+		fmt.Printf("fzgen: created %d files\n", len(files))
 	}
 
 	return 0
@@ -269,47 +414,35 @@ func hasPath(s string) bool {
 // outDirPkgName determines the package name for the directory that
 // contains outFile, or returns the empty string if it does not find an
 // existing package in that directory.
-func outDirPkgName(outFile string) string {
+func outDirPkgName(outFile string, buildFlags []string) (string, error) {
 	outDir, err := filepath.Abs(filepath.Dir(outFile))
 	if err != nil {
-		fail(err)
+		return "", err
 	}
 
 	isMod, err := isInModule(outDir)
 	if err != nil {
-		fail(fmt.Errorf("failed when checking if directory %q is a module: %v", outDir, err))
+		return "", fmt.Errorf("failed when checking if directory %q is a module: %v", outDir, err)
 	}
 	if !isMod {
-		fail(fmt.Errorf("output directory %q is not a module", outDir))
+		return "", fmt.Errorf("output directory %q is not a module", outDir)
 	}
 
 	// Determine our current package name using go list.
-	pkgNames, err := goList(outDir, "-e", "-f", "{{.Name}}", ".")
+	args := append(append([]string{"-e", "-f", "{{.Name}}"}, buildFlags...), ".")
+	pkgNames, err := goList(outDir, args...)
 	if err != nil {
-		fail(err)
+		return "", err
 	}
 	switch len(pkgNames) {
 	case 0:
 		// No .go files or possibly no valid .go files in the current dir.
-		return ""
+		return "", nil
 	case 1:
-		return pkgNames[0]
+		return pkgNames[0], nil
 	default:
-		fail(errors.New("unexpected"))
-	}
-	return ""
-}
-
-// fail prints an error to stderr and exits.
-func fail(err error) {
-	_, file, line, ok := runtime.Caller(1)
-	if ok {
-		file = filepath.Base(file)
-		fmt.Fprintf(os.Stderr, "fzgen: %s:%d: error: %v\n", file, line, err)
-	} else {
-		fmt.Fprintf(os.Stderr, "fzgen: error: %v\n", err)
+		return "", fmt.Errorf("unexpected multiple package names in %q: %v", outDir, pkgNames)
 	}
-	os.Exit(1)
 }
 
 func init() {
@@ -336,7 +469,4 @@ func init() {
 	}
 }
 
-var topCommentTmpl string = `
-// Edit if desired. Code generated by "fzgen %s".
-
-`
+var topCommentTmpl string = `// Edit if desired. Code generated by "fzgen %s".`
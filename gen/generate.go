@@ -0,0 +1,423 @@
+package gen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/tools/imports"
+)
+
+// Errors that cause a target package to be skipped, which are reported via Skipped.
+// These are often wrapped with additional detail, so use errors.Is to check for them.
+var (
+	ErrNoConstructorsMatch = errors.New("no matching constructor")
+	ErrNoFunctionsMatch    = errors.New("no fuzzable functions found")
+	ErrNoMethodsMatch      = errors.New("no methods found")
+	ErrNoSteps             = errors.New("no supported methods found")
+	ErrUnsupportedParams   = errors.New("unsupported parameters")
+)
+
+// Options configures Generate. The zero value generates independent wrappers for the
+// exported functions in the package in the current directory, which matches running
+// fzgen without any arguments. Most fields correspond to an fzgen flag of a similar name.
+type Options struct {
+	// Patterns are the target package patterns, such as "./..." or "example.com/foo".
+	// An empty list means ".".
+	Patterns []string
+
+	// Dir is the directory used to load the target packages and to resolve relative paths
+	// for Config and Output. An empty Dir means the current working directory.
+	Dir string
+
+	// Config is an optional path to a JSON config file with per-package settings.
+	// Non-zero fields in Options override the config file.
+	Config string
+
+	Func       string   // function regexp. empty means ".".
+	Ctor       string   // constructor regexp. empty means ".".
+	Exclude    []string // regexps for functions to skip, matching names like "Parse" or "Set.Add".
+	Chain      bool     // emit chains rather than independent wrappers.
+	Parallel   bool     // allow chains to run in parallel. requires Chain.
+	Unexported bool     // include unexported functions.
+	TypeArgs   string   // comma-separated types for instantiating generics. empty means the default.
+	Tags       []string // build tags used when loading packages, also emitted in a //go:build line.
+
+	// Params holds value hints for parameters, in the same format as the params in a config file.
+	Params map[string][]string
+
+	// Output is the output file name. If there are multiple target packages, it must
+	// be only a file name, and each file is placed in its target package's directory.
+	// Otherwise, a relative path is relative to Dir. Empty means autofuzz_test.go,
+	// or autofuzzchain_test.go if Chain is set.
+	Output string
+
+	DisableCtorInject bool // do not insert constructors when wrapping methods.
+	DisableSeeds      bool // do not emit seed corpus entries harvested from existing tests.
+	DisableDict       bool // do not emit a dictionary of literals and constants.
+
+	// Invariants is a comma-separated list of names of methods that check the consistency of a chain's
+	// target, such as "Validate,Len", which are called after each step. Empty means the default
+	// of "Validate,CheckInvariants,Len", and "none" disables the checks.
+	Invariants string
+
+	// Diff is the import path of a reference package with the same API as the target package,
+	// such as an older version or a simpler implementation. If set, Generate emits differential
+	// wrappers that call both packages with identical arguments and panic if the results differ.
+	// With Chain, the methods of types in both packages are compared via fz.ChainDiff.
+	Diff string
+
+	// CtorFallback is how a chain's target is created when there is no matching constructor:
+	// "none" to skip the type, "zero" for a zero value, or "fill" for a value populated via fz.Fill.
+	// Empty means "none".
+	CtorFallback string
+
+	// Merge merges with any existing output file, preserving wrappers marked
+	// with a //fzgen:keep comment as well as other declarations.
+	Merge bool
+
+	// ForceLocal emits references to the target package without a package qualifier,
+	// as if the output file were in the target package, even if it is not.
+	ForceLocal bool
+
+	// TopComment is placed after the package clause of each generated file,
+	// such as `// Code generated by mytool. DO NOT EDIT.`. Empty means no comment.
+	TopComment string
+
+	// Report, if non-nil, is called with a Record for each candidate function,
+	// including functions that were skipped.
+	Report func(Record)
+}
+
+// File is a generated file. Generate does not write any files.
+type File struct {
+	Path    string // absolute path of the output file
+	PkgPath string // import path of the target package
+	Content []byte // the formatted contents of the file
+
+	Merged   bool     // whether Content includes an existing file's contents due to Options.Merge
+	Removed  []string // wrappers removed from the existing file during a merge
+	Stale    []string // wrappers kept during a merge that are no longer generated
+	Replaced []string // unmarked wrappers that differed from the generated wrappers that replaced them during a merge
+	Warnings []string // non-fatal problems, such as failing to adjust imports
+}
+
+// Skipped reports a target package for which no file was generated.
+type Skipped struct {
+	PkgPath string
+	Err     error // wraps ErrNoConstructorsMatch, ErrUnsupportedParams, or one of the other errors above.
+}
+
+// Generate generates fuzzing wrappers for the target packages described by opts,
+// and returns the resulting files without writing them. A package with no
+// candidate functions is omitted, and a package for which no wrappers
+// could be generated is reported in the returned Skipped list.
+// An error is returned if the options are invalid or the packages fail to load.
+func Generate(ctx context.Context, opts Options) ([]File, []Skipped, error) {
+	return generate(ctx, opts, opts.settings())
+}
+
+// settings returns the genSettings set by the non-zero fields in opts.
+func (opts Options) settings() genSettings {
+	s := genSettings{
+		Func:     opts.Func,
+		Ctor:     opts.Ctor,
+		Exclude:  opts.Exclude,
+		Output:   opts.Output,
+		TypeArgs: opts.TypeArgs,
+		Params:   opts.Params,
+
+		CtorFallback: opts.CtorFallback,
+		Diff:         opts.Diff,
+		Invariants:   opts.Invariants,
+	}
+	if opts.Chain {
+		s.Chain = boolPtr(true)
+	}
+	if opts.Parallel {
+		s.Parallel = boolPtr(true)
+	}
+	if opts.Unexported {
+		s.Unexported = boolPtr(true)
+	}
+	if opts.DisableCtorInject {
+		s.CtorInject = boolPtr(false)
+	}
+	if opts.DisableSeeds {
+		s.Seeds = boolPtr(false)
+	}
+	if opts.DisableDict {
+		s.Dict = boolPtr(false)
+	}
+	return s
+}
+
+// generate does the work for Generate. explicit holds the settings that override any config file,
+// which allows FzgenMain to pass the flags that were set, including flags explicitly set to false.
+func generate(ctx context.Context, opts Options, explicit genSettings) ([]File, []Skipped, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkgPatterns := opts.Patterns
+	if len(pkgPatterns) == 0 {
+		pkgPatterns = []string{"."}
+	}
+	flagSettings := defaultSettings().merge(explicit)
+
+	var cfg *config
+	if opts.Config != "" {
+		path := opts.Config
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		cfg, err = loadConfig(path)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if cfg == nil && *flagSettings.Parallel && !*flagSettings.Chain {
+		return nil, nil, errors.New("parallel requires chain")
+	}
+	if err := checkCtorFallback(flagSettings.CtorFallback); err != nil {
+		return nil, nil, err
+	}
+
+	buildLine, err := buildConstraint(dir, opts.Tags)
+	if err != nil {
+		return nil, nil, err
+	}
+	lo := loadOptions{ctx: ctx, dir: dir, buildFlags: tagsBuildFlags(opts.Tags)}
+
+	// Search for functions in the requested packages that match the supplied func and ctor regex.
+	// If we have a config file, the func and ctor regex can vary by package, so we start with
+	// all functions and then filter based on the settings for each package.
+	var pkgs []*pkg
+	if cfg == nil {
+		flags := flagExcludeFuzzPrefix | flagMultiMatch
+		if !*flagSettings.Unexported {
+			flags |= flagRequireExported
+		}
+		pkgs, err = findFuncsGrouped(pkgPatterns, flagSettings.Func, flagSettings.Ctor, lo, flags)
+	} else {
+		pkgs, err = findFuncsGrouped(pkgPatterns, ".", ".", lo, flagExcludeFuzzPrefix|flagMultiMatch)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Determine the settings for each package, which are all the same unless we have a config file.
+	settings := make([]genSettings, len(pkgs))
+	for i := range pkgs {
+		settings[i] = flagSettings
+		if cfg == nil {
+			if len(flagSettings.Exclude) > 0 {
+				if pkgs[i], err = flagSettings.filter(pkgs[i]); err != nil {
+					return nil, nil, err
+				}
+			}
+			continue
+		}
+		pkgSettings, err := cfg.settingsFor(pkgs[i].pkgPath, lo.buildFlags)
+		if err != nil {
+			return nil, nil, err
+		}
+		settings[i] = defaultSettings().merge(pkgSettings).merge(explicit)
+		if *settings[i].Parallel && !*settings[i].Chain {
+			return nil, nil, fmt.Errorf("%s: parallel requires chain", pkgs[i].pkgPath)
+		}
+		if err := checkCtorFallback(settings[i].CtorFallback); err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", pkgs[i].pkgPath, err)
+		}
+		pkgs[i], err = settings[i].filter(pkgs[i])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Check if we are looking at one package vs. multiple.
+	for i := range pkgs {
+		if len(pkgs) > 1 && hasPath(settings[i].outFile()) {
+			return nil, nil, errors.New("-o can only specify a file name and not a path when the package pattern matches multiple packages")
+		}
+	}
+
+	var topComment string
+	if opts.TopComment != "" {
+		topComment = "\n" + opts.TopComment + "\n\n"
+	}
+
+	// Load any reference packages for differential wrappers, which are shared across target packages.
+	refPkgs := make(map[string]*types.Package)
+	for i := range pkgs {
+		if settings[i].Diff == "" || refPkgs[settings[i].Diff] != nil {
+			continue
+		}
+		refPkgs[settings[i].Diff], err = loadDiffPkg(settings[i].Diff, lo)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Loop over our packages, and start our real work.
+	var files []File
+	var skipped []Skipped
+	for i := range pkgs {
+		if len(pkgs[i].functions) == 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		s := settings[i]
+		file := File{PkgPath: pkgs[i].pkgPath}
+
+		// Determine what output file we will create, and what package name we will use in it.
+		var wrapperPkgName string
+		outFile := s.outFile()
+		targetPkgName := pkgs[i].functions[0].PkgName
+		switch {
+		case len(pkgs) > 1:
+			// Specifying multiple packages via a pattern creates an output file in each package's directory.
+			outFile = filepath.Join(pkgs[i].functions[0].PkgDir, outFile)
+			wrapperPkgName = targetPkgName
+		case len(pkgs) == 1:
+			// When the target is a single package overall, we default to placing the output file in the working directory,
+			// with the ability to set a more specific output file path via -o. For the working directory or some other user-supplied
+			// output location, there might already be a package there, so we need to look up that package name if it exists.
+			if !filepath.IsAbs(outFile) {
+				outFile = filepath.Join(dir, outFile)
+			}
+			wrapperPkgName, err = outDirPkgName(outFile, lo.buildFlags)
+			if err != nil {
+				return nil, nil, err
+			}
+			if wrapperPkgName == "" {
+				// We did not find a package name in our destination (e.g., might not have any .go files there),
+				// so make up a new package name.
+				wrapperPkgName = targetPkgName + "fuzz"
+			}
+		default:
+			panic("impossible")
+		}
+		file.Path, err = filepath.Abs(outFile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// If the output file will end up in the target package dir, we set qualifyAll to false
+		// so that emitted references to types from the target package will not be qualified with the package prefix.
+		targetDir, err := filepath.Abs(pkgs[i].functions[0].PkgDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		qualifyAll := targetDir != filepath.Dir(file.Path)
+
+		if opts.ForceLocal {
+			qualifyAll = false
+		}
+
+		// Harvest seed corpus values from the target package's existing tests.
+		var seeds seedCalls
+		if *s.Seeds && !*s.Chain && s.Diff == "" {
+			seeds, err = harvestSeeds(pkgs[i].functions[0].PkgDir, pkgs[i].pkgPath, targetPkgName)
+			if err != nil {
+				file.Warnings = append(file.Warnings, fmt.Sprintf("continuing after failing to harvest seeds from tests: %v", err))
+			}
+		}
+
+		// Harvest a dictionary of literals and constants from the target package.
+		var dict *dictionary
+		if *s.Dict {
+			dict, err = harvestDictionary(pkgs[i].functions[0].PkgDir, pkgs[i].functions[0].TypesFunc.Pkg())
+			if err != nil {
+				file.Warnings = append(file.Warnings, fmt.Sprintf("continuing after failing to harvest dictionary: %v", err))
+			}
+		}
+
+		wrapperOpts := wrapperOptions{
+			qualifyAll:         qualifyAll,
+			insertConstructors: *s.CtorInject,
+			parallel:           *s.Parallel,
+			topComment:         topComment,
+			buildConstraint:    buildLine,
+			typeArgs:           splitTypeArgs(s.TypeArgs),
+			paramHints:         s.Params,
+			seeds:              seeds,
+			dictionary:         dict,
+			report:             opts.Report,
+			ctorFallback:       s.CtorFallback,
+			invariants:         splitInvariants(s.Invariants),
+		}
+
+		// Do the actual work of emitting our wrappers.
+		var out []byte
+		switch {
+		case s.Diff != "":
+			out, err = emitDiffWrappers(pkgs[i].pkgPath, pkgs[i], refPkgs[s.Diff], wrapperPkgName, *s.Chain, wrapperOpts)
+		case !*s.Chain:
+			out, err = emitIndependentWrappers(pkgs[i].pkgPath, pkgs[i], wrapperPkgName, wrapperOpts)
+		default:
+			out, err = emitChainWrappers(pkgs[i].pkgPath, pkgs[i], wrapperPkgName, wrapperOpts)
+		}
+		switch {
+		case errors.Is(err, ErrUnsupportedParams), errors.Is(err, ErrNoMethodsMatch), errors.Is(err, ErrNoSteps), errors.Is(err, ErrNoFunctionsMatch):
+			skipped = append(skipped, Skipped{PkgPath: pkgs[i].pkgPath, Err: err})
+			continue
+		case errors.Is(err, ErrNoConstructorsMatch):
+			skipped = append(skipped, Skipped{PkgPath: pkgs[i].pkgPath, Err: fmt.Errorf("%w for -ctor pattern %q", err, s.Ctor)})
+			continue
+		case err != nil:
+			return nil, nil, err
+		}
+
+		// If requested, merge with any existing output file.
+		if opts.Merge {
+			existing, err := ioutil.ReadFile(file.Path)
+			switch {
+			case err == nil:
+				var res mergeResult
+				out, res, err = mergeWrappers(existing, out)
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to merge with %s: %v", file.Path, err)
+				}
+				file.Merged, file.Removed, file.Stale, file.Replaced = true, res.removed, res.stale, res.replaced
+			case !os.IsNotExist(err):
+				return nil, nil, err
+			}
+		}
+
+		// Fix up any needed imports.
+		file.Content, err = imports.Process(file.Path, out, nil)
+		if err != nil {
+			file.Warnings = append(file.Warnings, fmt.Sprintf("continuing after failing to automatically adjust imports: %v", err))
+			file.Content = out
+		}
+		files = append(files, file)
+	}
+	return files, skipped, nil
+}
+
+// loadDiffPkg loads the reference package for differential wrappers,
+// which must be a single package.
+func loadDiffPkg(pattern string, lo loadOptions) (*types.Package, error) {
+	pkgs, err := findFuncsGrouped([]string{pattern}, ".", ".", lo, flagExcludeFuzzPrefix|flagMultiMatch)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 || len(pkgs[0].functions) == 0 {
+		return nil, fmt.Errorf("diff package %q must match a single package with functions, but matched %d packages", pattern, len(pkgs))
+	}
+	return pkgs[0].functions[0].TypesFunc.Pkg(), nil
+}
@@ -0,0 +1,149 @@
+package gen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerate(t *testing.T) {
+	pkgPattern := "github.com/thepudds/fzgen/examples/inputs/test-constructor-injection"
+	tests := []struct {
+		name        string
+		opts        Options
+		wantErr     bool
+		wantFile    string // base name of the expected output file, if any
+		wantContent string // substring expected in the output file
+		wantSkipErr error  // expected error for a skipped package, if any
+	}{
+		{
+			name:        "independent wrappers",
+			opts:        Options{Patterns: []string{pkgPattern}},
+			wantFile:    "autofuzz_test.go",
+			wantContent: "func Fuzz_A_PtrMethodWithArg(f *testing.F)",
+		},
+		{
+			name:        "chain wrappers",
+			opts:        Options{Patterns: []string{pkgPattern}, Chain: true, Ctor: "^NewAPtr$"},
+			wantFile:    "autofuzzchain_test.go",
+			wantContent: "func Fuzz_NewAPtr_Chain(f *testing.F)",
+		},
+		{
+			name:        "top comment",
+			opts:        Options{Patterns: []string{pkgPattern}, TopComment: "// Code generated by test. DO NOT EDIT."},
+			wantFile:    "autofuzz_test.go",
+			wantContent: "// Code generated by test. DO NOT EDIT.",
+		},
+		{
+			name:        "no matching constructor",
+			opts:        Options{Patterns: []string{pkgPattern}, Chain: true, Ctor: "NoMatch"},
+			wantSkipErr: ErrNoConstructorsMatch,
+		},
+		{
+			name:    "parallel requires chain",
+			opts:    Options{Patterns: []string{pkgPattern}, Parallel: true},
+			wantErr: true,
+		},
+		{
+			name:        "chain ctor fallback",
+			opts:        Options{Patterns: []string{pkgPattern}, Chain: true, Ctor: "^NewAPtr$", CtorFallback: "zero"},
+			wantFile:    "autofuzzchain_test.go",
+			wantContent: "func Fuzz_MyNullUUID_Chain(f *testing.F)",
+		},
+		{
+			name:    "invalid ctor fallback",
+			opts:    Options{Patterns: []string{pkgPattern}, Chain: true, CtorFallback: "bad"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid tags",
+			opts:    Options{Patterns: []string{pkgPattern}, Tags: []string{"bad!tag"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			files, skipped, err := Generate(context.Background(), tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Generate() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate() failed: %v", err)
+			}
+
+			if tt.wantSkipErr != nil {
+				if len(files) != 0 || len(skipped) != 1 {
+					t.Fatalf("Generate() returned %d files and %d skipped, want 0 files and 1 skipped", len(files), len(skipped))
+				}
+				if !errors.Is(skipped[0].Err, tt.wantSkipErr) {
+					t.Errorf("Generate() skipped with error %v, want %v", skipped[0].Err, tt.wantSkipErr)
+				}
+				return
+			}
+
+			if len(files) != 1 || len(skipped) != 0 {
+				t.Fatalf("Generate() returned %d files and %d skipped, want 1 file and 0 skipped", len(files), len(skipped))
+			}
+			if got := filepath.Base(files[0].Path); got != tt.wantFile {
+				t.Errorf("Generate() file name = %s, want %s", got, tt.wantFile)
+			}
+			if !filepath.IsAbs(files[0].Path) {
+				t.Errorf("Generate() file path %s is not absolute", files[0].Path)
+			}
+			if files[0].PkgPath != pkgPattern {
+				t.Errorf("Generate() file PkgPath = %s, want %s", files[0].PkgPath, pkgPattern)
+			}
+			if !bytes.Contains(files[0].Content, []byte(tt.wantContent)) {
+				t.Errorf("Generate() content missing %q:\n%s", tt.wantContent, files[0].Content)
+			}
+		})
+	}
+}
+
+func TestGenerateReport(t *testing.T) {
+	pkgPattern := "github.com/thepudds/fzgen/examples/inputs/test-types"
+	var records []Record
+	opts := Options{
+		Patterns: []string{pkgPattern},
+		Func:     "^(Short1|TypesShortListFill|TypesShortListSkip1|Pointers)$",
+		Report:   func(r Record) { records = append(records, r) },
+	}
+	if _, _, err := Generate(context.Background(), opts); err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	want := map[string]Record{
+		"Short1": {
+			Package: pkgPattern, Func: "Short1", Wrapper: "Fuzz_Short1", Status: StatusNative,
+		},
+		"TypesShortListFill": {
+			Package: pkgPattern, Func: "TypesShortListFill", Wrapper: "Fuzz_TypesShortListFill", Status: StatusFill,
+		},
+		"TypesShortListSkip1": {
+			Package: pkgPattern, Func: "TypesShortListSkip1", Status: StatusSkipped,
+			Unsupported: "chan bool", Reason: "parameters include func, chan, or unsupported interface",
+		},
+		"Pointers": {
+			Package: pkgPattern, Receiver: "*TypesNilCheck", Func: "Pointers", Wrapper: "Fuzz_TypesNilCheck_Pointers",
+			Status: StatusFill, Constructor: "NewTypesNilCheck",
+		},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("Generate() reported %d records, want %d: %+v", len(records), len(want), records)
+	}
+	for _, got := range records {
+		if diff := cmp.Diff(want[got.Func], got); diff != "" {
+			t.Errorf("Generate() record for %s mismatch (-want +got):\n%s", got.Func, diff)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package gen
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/thepudds/fzgen/gen/internal/mod"
+)
+
+// defaultTypeArgs is the default set of types used to instantiate a type parameter
+// whose constraint does not list its allowed types via a union (such as 'any' or 'comparable').
+const defaultTypeArgs = "int,string,[]byte"
+
+// maxInstantiations caps how many instantiations we emit for a single generic function,
+// which otherwise grows multiplicatively with the count of type parameters.
+const maxInstantiations = 9
+
+// instantiate returns the list of instantiated functions for a generic function or a method on a
+// generic type, with one result per combination of concrete type arguments we selected.
+// If function is not generic, instantiate returns function unchanged.
+// typeArgs is the list of type expressions (such as "int" or "[]byte") used for type parameters
+// that are not constrained by a union, and is evaluated in the scope of the function's package.
+// instantiate returns an error if any type parameter cannot be instantiated.
+func instantiate(function mod.Func, typeArgs []string) ([]mod.Func, error) {
+	f := function.TypesFunc
+	sig, ok := f.Type().(*types.Signature)
+	if !ok {
+		return nil, fmt.Errorf("function %s is not *types.Signature (%+v)", function, f)
+	}
+
+	// Determine what we are instantiating. For a method on a generic type,
+	// we instantiate the receiver's named type and then look up the method on the instantiated type.
+	// For a generic function, we instantiate the signature.
+	var recvN *types.Named
+	var tparams *types.TypeParamList
+	switch {
+	case sig.RecvTypeParams().Len() > 0:
+		n := receiver(f)
+		if n == nil {
+			return nil, fmt.Errorf("failed to determine generic receiver type for %s", function)
+		}
+		recvN = n.Origin()
+		tparams = recvN.TypeParams()
+	case sig.TypeParams().Len() > 0:
+		tparams = sig.TypeParams()
+	default:
+		return []mod.Func{function}, nil
+	}
+
+	if typeArgs == nil {
+		typeArgs = splitTypeArgs(defaultTypeArgs)
+	}
+	defaults, err := evalTypeArgs(f.Pkg(), typeArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine the candidate concrete types for each type parameter.
+	candidates := make([][]types.Type, tparams.Len())
+	for i := 0; i < tparams.Len(); i++ {
+		tp := tparams.At(i)
+		candidates[i] = constraintTypes(tp.Constraint())
+		if len(candidates[i]) == 0 {
+			candidates[i] = defaults
+		}
+		if len(candidates[i]) == 0 {
+			return nil, fmt.Errorf("%w: %s has type parameter %s with no usable type arguments", ErrUnsupportedParams, function.FuncName, tp)
+		}
+	}
+
+	// Instantiate each combination of type arguments, skipping any combination
+	// that does not satisfy the constraints.
+	var result []mod.Func
+	for _, targs := range combinations(candidates) {
+		if len(result) >= maxInstantiations {
+			break
+		}
+		var instF *types.Func
+		if recvN != nil {
+			inst, err := types.Instantiate(nil, recvN, targs, true)
+			if err != nil {
+				continue
+			}
+			obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(inst), false, f.Pkg(), f.Name())
+			m, ok := obj.(*types.Func)
+			if !ok {
+				continue
+			}
+			instF = m
+		} else {
+			inst, err := types.Instantiate(nil, sig, targs, true)
+			if err != nil {
+				continue
+			}
+			instF = types.NewFunc(f.Pos(), f.Pkg(), f.Name(), inst.(*types.Signature))
+		}
+		instantiated := function
+		instantiated.TypesFunc = instF
+		instantiated.TypeArgs = targs
+		result = append(result, instantiated)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%w: %s has type parameters that could not be instantiated", ErrUnsupportedParams, function.FuncName)
+	}
+	return result, nil
+}
+
+// constraintTypes returns the types listed in any unions within a type parameter's constraint,
+// such as int and float64 for 'interface{ ~int | float64 }'. For a tilde term like ~int, we use int.
+// Terms that themselves refer to type parameters (such as ~[]E) are skipped.
+// constraintTypes returns nil if the constraint has no unions, such as for 'any' or 'comparable'.
+func constraintTypes(constraint types.Type) []types.Type {
+	iface, ok := constraint.Underlying().(*types.Interface)
+	if !ok {
+		return nil
+	}
+	var result []types.Type
+	seen := make(map[string]bool)
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		var terms []types.Type
+		switch e := iface.EmbeddedType(i).(type) {
+		case *types.Union:
+			for j := 0; j < e.Len(); j++ {
+				terms = append(terms, e.Term(j).Type())
+			}
+		default:
+			// Possibly a named constraint such as constraints.Integer, or a single type such as ~int.
+			if _, ok := e.Underlying().(*types.Interface); ok {
+				terms = constraintTypes(e)
+			} else {
+				terms = append(terms, e)
+			}
+		}
+		for _, t := range terms {
+			if _, ok := t.Underlying().(*types.Interface); ok || containsTypeParam(t) {
+				continue
+			}
+			if s := t.String(); !seen[s] {
+				seen[s] = true
+				result = append(result, t)
+			}
+		}
+	}
+	return result
+}
+
+// containsTypeParam reports whether t refers to a type parameter.
+// It checks the common composite types, which is sufficient for the union terms we see in practice.
+func containsTypeParam(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.TypeParam:
+		return true
+	case *types.Pointer:
+		return containsTypeParam(t.Elem())
+	case *types.Slice:
+		return containsTypeParam(t.Elem())
+	case *types.Array:
+		return containsTypeParam(t.Elem())
+	case *types.Chan:
+		return containsTypeParam(t.Elem())
+	case *types.Map:
+		return containsTypeParam(t.Key()) || containsTypeParam(t.Elem())
+	case *types.Named:
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if containsTypeParam(t.TypeArgs().At(i)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// combinations returns the cartesian product of the candidate types for each type parameter,
+// in a deterministic order with the first type parameter varying slowest.
+func combinations(candidates [][]types.Type) [][]types.Type {
+	result := [][]types.Type{nil}
+	for _, cands := range candidates {
+		var next [][]types.Type
+		for _, prefix := range result {
+			for _, c := range cands {
+				combo := append(append([]types.Type{}, prefix...), c)
+				next = append(next, combo)
+			}
+		}
+		result = next
+	}
+	return result
+}
+
+// evalTypeArgs evaluates a list of type expressions in the scope of pkg,
+// which allows for example "int", "[]byte", or the name of a type declared in pkg.
+func evalTypeArgs(pkg *types.Package, exprs []string) ([]types.Type, error) {
+	var result []types.Type
+	for _, expr := range exprs {
+		tv, err := types.Eval(token.NewFileSet(), pkg, token.NoPos, expr)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating type argument %q: %v", expr, err)
+		}
+		if !tv.IsType() {
+			return nil, fmt.Errorf("type argument %q is not a type", expr)
+		}
+		result = append(result, tv.Type)
+	}
+	return result, nil
+}
+
+// splitTypeArgs splits a comma-separated list of type expressions, such as "int,string,map[string]int".
+// Commas nested inside brackets, braces or parens are not treated as separators.
+func splitTypeArgs(s string) []string {
+	var result []string
+	var depth, start int
+	for i, r := range s {
+		switch r {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		result = append(result, last)
+	}
+	return result
+}
+
+// typeArgsSuffix returns a string suitable for use in an identifier that describes
+// a list of type arguments, such as "int_string" or "bytes".
+func typeArgsSuffix(targs []types.Type, qualifier types.Qualifier) string {
+	replacer := strings.NewReplacer("[]", "slice_", "*", "ptr_", "map[", "map_", "[", "_", "]", "_", ".", "_", ",", "_", " ", "")
+	var parts []string
+	for _, t := range targs {
+		s := types.TypeString(t, qualifier)
+		if s == "[]byte" || s == "[]uint8" {
+			s = "bytes"
+		}
+		parts = append(parts, strings.Trim(replacer.Replace(s), "_"))
+	}
+	return strings.Join(parts, "_")
+}
+
+// typeArgsList returns the bracketed list of type arguments needed to
+// explicitly instantiate a generic function, such as "[int, string]",
+// or the empty string if there are no type arguments.
+func typeArgsList(targs []types.Type, qualifier types.Qualifier) string {
+	if len(targs) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, t := range targs {
+		parts = append(parts, types.TypeString(t, qualifier))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// recvTypeName returns the local name for a receiver's named type, such as "Set" for Set[int].
+func recvTypeName(n *types.Named, localQualifier types.Qualifier) string {
+	if n.TypeArgs().Len() > 0 {
+		return n.Obj().Name()
+	}
+	return types.TypeString(n.Obj().Type(), localQualifier)
+}
+
+// instantiateConstructors returns the possible constructors for the named type recvN.
+// A generic constructor whose result is the generic origin of recvN (such as New[T any]() *Set[T]
+// for Set[int]) is instantiated with the type arguments of recvN. Generic constructors that cannot be
+// instantiated that way are dropped, and non-generic constructors are returned unchanged.
+// recvN may be nil, in which case only the non-generic constructors are returned.
+func instantiateConstructors(ctors []mod.Func, recvN *types.Named) []mod.Func {
+	var result []mod.Func
+	for _, ctor := range ctors {
+		sig, ok := ctor.TypesFunc.Type().(*types.Signature)
+		if !ok {
+			continue
+		}
+		if sig.TypeParams().Len() == 0 {
+			result = append(result, ctor)
+			continue
+		}
+		if recvN == nil || recvN.TypeArgs().Len() == 0 {
+			continue
+		}
+		ctorResultN, _ := constructorResult(ctor.TypesFunc)
+		if ctorResultN == nil || ctorResultN.Origin().Obj() != recvN.Origin().Obj() {
+			continue
+		}
+
+		// Map the constructor's type parameters to the receiver's type arguments
+		// based on where they appear in the constructor's result type.
+		tparams := sig.TypeParams()
+		targs := make([]types.Type, tparams.Len())
+		for i := 0; i < ctorResultN.TypeArgs().Len() && i < recvN.TypeArgs().Len(); i++ {
+			tp, ok := ctorResultN.TypeArgs().At(i).(*types.TypeParam)
+			if !ok || tp.Index() >= tparams.Len() || tparams.At(tp.Index()) != tp {
+				continue
+			}
+			targs[tp.Index()] = recvN.TypeArgs().At(i)
+		}
+		complete := true
+		for _, t := range targs {
+			if t == nil {
+				complete = false
+				break
+			}
+		}
+		if !complete {
+			continue
+		}
+		inst, err := types.Instantiate(nil, sig, targs, true)
+		if err != nil {
+			continue
+		}
+		instantiated := ctor
+		instantiated.TypesFunc = types.NewFunc(ctor.TypesFunc.Pos(), ctor.TypesFunc.Pkg(), ctor.TypesFunc.Name(), inst.(*types.Signature))
+		instantiated.TypeArgs = targs
+		result = append(result, instantiated)
+	}
+	return result
+}
@@ -14,29 +14,43 @@ import (
 )
 
 type wrapperOptions struct {
-	qualifyAll         bool   // qualify all variables with package name
-	insertConstructors bool   // attempt to insert suitable constructors when wrapping methods
-	parallel           bool   // set the Parallel flag in the emitted code, which allows steps of a chain to run in parallel
-	topComment         string // additional comment for top of generated file.
+	qualifyAll         bool                // qualify all variables with package name
+	insertConstructors bool                // attempt to insert suitable constructors when wrapping methods
+	parallel           bool                // set the Parallel flag in the emitted code, which allows steps of a chain to run in parallel
+	topComment         string              // additional comment for top of generated file.
+	typeArgs           []string            // type expressions used to instantiate unconstrained type parameters. nil means defaultTypeArgs.
+	paramHints         map[string][]string // values to emit as seed corpus entries for natively supported params, keyed by param name
+	buildConstraint    string              // optional //go:build line for the top of generated file.
+	seeds              seedCalls           // constant args from calls in existing tests, emitted as seed corpus entries
+	dictionary         *dictionary         // literals and constants from the target package, used by fz.Fill. nil means none.
+	dictUsed           *bool               // set by newFuzzerCall if an emitted wrapper uses the dictionary. nil means not tracked.
+	report             func(Record)        // called with the outcome for each candidate function. nil means no reporting.
+	ctorFallback       string              // how to create a chain's target without a matching constructor. empty means ctorFallbackNone.
+	invariants         []string            // names of methods to call after each step of a chain to check the target's consistency.
+}
+
+// The ways to create a chain's target when there is no matching constructor.
+const (
+	ctorFallbackNone = "none" // skip the target type
+	ctorFallbackZero = "zero" // declare the target as a zero value
+	ctorFallbackFill = "fill" // declare the target and populate it via fz.Fill
+)
+
+// ctorFallbackEnabled reports if a chain's target can be created without a constructor.
+func (o wrapperOptions) ctorFallbackEnabled() bool {
+	return o.ctorFallback == ctorFallbackZero || o.ctorFallback == ctorFallbackFill
 }
 
 type emitFunc func(format string, args ...interface{})
 
-var (
-	errNoConstructorsMatch = errors.New("no matching constructor")
-	errNoFunctionsMatch    = errors.New("no fuzzable functions found")
-	errNoMethodsMatch      = errors.New("no methods found")
-	errNoSteps             = errors.New("no supported methods found")
-	errUnsupportedParams   = errors.New("unsupported parameters")
-	errSilentSkip          = errors.New("silently skipping wrapper generation")
-)
+var errSilentSkip = errors.New("silently skipping wrapper generation")
 
 // emitIndependentWrappers emits fuzzing wrappers where possible for the list of functions passed in.
 // It might skip a function if it has no input parameters, or if it has a non-fuzzable parameter
 // type such as interface{}.
 func emitIndependentWrappers(pkgPath string, pkgFuncs *pkg, wrapperPkgName string, options wrapperOptions) ([]byte, error) {
 	if len(pkgFuncs.functions) == 0 {
-		return nil, fmt.Errorf("%w: 0 matching functions", errNoFunctionsMatch)
+		return nil, fmt.Errorf("%w: 0 matching functions", ErrNoFunctionsMatch)
 	}
 
 	// prepare the output
@@ -47,8 +61,11 @@ func emitIndependentWrappers(pkgPath string, pkgFuncs *pkg, wrapperPkgName strin
 	}
 
 	// emit the intro material
+	if options.buildConstraint != "" {
+		emit("%s\n\n", options.buildConstraint)
+	}
 	emit("package %s\n\n", wrapperPkgName)
-	emit(options.topComment)
+	emit("%s", options.topComment)
 	emit("import (\n")
 	emit("\t\"testing\"\n")
 	if options.qualifyAll {
@@ -57,6 +74,10 @@ func emitIndependentWrappers(pkgPath string, pkgFuncs *pkg, wrapperPkgName strin
 	emit("\t\"github.com/thepudds/fzgen/fuzzer\"\n")
 	emit(")\n\n")
 
+	// Track whether any wrapper uses our dictionary.
+	var dictUsed bool
+	options.dictUsed = &dictUsed
+
 	// put our functions we want to wrap into a deterministic order
 	sort.Slice(pkgFuncs.functions, func(i, j int) bool {
 		// types.Func.String outputs strings like:
@@ -71,31 +92,53 @@ func emitIndependentWrappers(pkgPath string, pkgFuncs *pkg, wrapperPkgName strin
 	var firstErr error
 	var success bool
 	for _, function := range pkgFuncs.functions {
-		var constructors []mod.Func
-		if options.insertConstructors {
-			for _, constructor := range pkgFuncs.constructors {
-				// Skip over any candidate constructors with unsupported params.
-				ctorInputParams := params(constructor.TypesFunc)
-				support, _ := checkParamSupport(ctorInputParams)
-				if support == noSupport {
-					continue
-				}
-				constructors = append(constructors, constructor)
+		// A generic function or a method on a generic type results in one wrapper per instantiation.
+		instantiated, err := instantiate(function, options.typeArgs)
+		if err != nil {
+			emit("// skipping %s: %v\n\n", function.FuncName, err)
+			options.skip(function, err.Error())
+			if firstErr == nil {
+				firstErr = err
 			}
+			continue
 		}
+		for _, inst := range instantiated {
+			var constructors []mod.Func
+			if options.insertConstructors {
+				// Generic constructors are instantiated to match the receiver, if any.
+				for _, constructor := range instantiateConstructors(pkgFuncs.constructors, receiver(inst.TypesFunc)) {
+					// Skip over any candidate constructors with unsupported params.
+					ctorInputParams := params(constructor.TypesFunc)
+					support, _ := checkParamSupport(ctorInputParams)
+					if support == noSupport {
+						continue
+					}
+					constructors = append(constructors, constructor)
+				}
+			}
 
-		err := emitIndependentWrapper(emit, function, constructors, options.qualifyAll)
-		if err != nil && firstErr == nil {
-			firstErr = err
-		}
-		if err == nil {
-			success = true
+			err := emitIndependentWrapper(emit, inst, constructors, options)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			if err == nil {
+				success = true
+			}
 		}
 	}
+	// Emit roundtrip wrappers for any pairs of inverse functions, such as Quote and Unquote.
+	if emitInverseWrappers(emit, pkgFuncs.functions, options) {
+		success = true
+	}
 	if !success {
 		return nil, firstErr
 	}
 
+	// Emit our dictionary at the end if any of our wrappers use fz.Fill.
+	if dictUsed {
+		emitDictionary(emit, options.dictionary, dictVarName)
+	}
+
 	return buf.Bytes(), nil
 }
 
@@ -111,8 +154,9 @@ type paramRepr struct {
 // emitIndependentWrapper emits one fuzzing wrapper if possible.
 // It takes a list of possible constructors to insert into the wrapper body if the
 // constructor is suitable for creating the receiver of a wrapped method.
-// qualifyAll indicates if all variables should be qualified with their package.
-func emitIndependentWrapper(emit emitFunc, function mod.Func, constructors []mod.Func, qualifyAll bool) error {
+// options.qualifyAll indicates if all variables should be qualified with their package.
+func emitIndependentWrapper(emit emitFunc, function mod.Func, constructors []mod.Func, options wrapperOptions) error {
+	qualifyAll := options.qualifyAll
 	f := function.TypesFunc
 	wrappedSig, ok := f.Type().(*types.Signature)
 	if !ok {
@@ -137,11 +181,16 @@ func emitIndependentWrapper(emit emitFunc, function mod.Func, constructors []mod
 		if err != nil {
 			// output to stderr, but don't treat as fatal error.
 			fmt.Fprintf(os.Stderr, "genfuzzfuncs: warning: createWrapper: failed to determine receiver type: %v: %v\n", recv, err)
+			options.skip(function, fmt.Sprintf("failed to determine receiver type: %v", err))
 			return nil
 		}
-		recvNamedTypeLocalName := types.TypeString(n.Obj().Type(), localQualifier)
+		recvNamedTypeLocalName := recvTypeName(n, localQualifier)
 		wrapperName = fmt.Sprintf("Fuzz_%s_%s", recvNamedTypeLocalName, f.Name())
 	}
+	if len(function.TypeArgs) > 0 {
+		// An instantiated generic function or method, such as Fuzz_Sum_int or Fuzz_Set_Add_string.
+		wrapperName = fmt.Sprintf("%s_%s", wrapperName, typeArgsSuffix(function.TypeArgs, localQualifier))
+	}
 
 	// Start building up our list of parameters we will use in input
 	// parameters to the new wrapper func we are about to emit.
@@ -164,23 +213,34 @@ func emitIndependentWrapper(emit emitFunc, function mod.Func, constructors []mod
 	// 			r.Read(b)
 	// 		})
 	var ctorReplace ctorMatch
+	var paramSources []paramSource // the function or constructor each input param is for, used for seed corpus values
 	if recv != nil {
 		var paramsToAdd []*types.Var
 		ctorReplace, paramsToAdd, err = constructorReplace(recv, constructors)
 		if err != nil {
+			options.skip(function, err.Error())
 			return err
 		}
 		inputParams = append(inputParams, paramsToAdd...)
+		for i := range paramsToAdd {
+			if ctorReplace.sig != nil {
+				paramSources = append(paramSources, paramSource{f: ctorReplace.f, arg: i})
+			} else {
+				paramSources = append(paramSources, paramSource{f: f, arg: -1})
+			}
+		}
 	}
 
 	// Also add in the parameters for the function under test.
 	for i := 0; i < wrappedSig.Params().Len(); i++ {
 		v := wrappedSig.Params().At(i)
 		inputParams = append(inputParams, v)
+		paramSources = append(paramSources, paramSource{f: f, arg: i})
 	}
 	if len(inputParams) == 0 {
 		// skip this wrapper, not useful for fuzzing if no inputs (no receiver, no parameters).
-		return fmt.Errorf("%w: %s has 0 input params", errNoFunctionsMatch, function.FuncName)
+		options.skip(function, "no input parameters")
+		return fmt.Errorf("%w: %s has 0 input params", ErrNoFunctionsMatch, function.FuncName)
 	}
 
 	paramReprs := make([]paramRepr, len(inputParams))
@@ -197,12 +257,29 @@ func emitIndependentWrapper(emit emitFunc, function mod.Func, constructors []mod
 	if support == noSupport {
 		// skip this wrapper.
 		emit("// skipping %s because parameters include func, chan, or unsupported interface: %v\n\n", wrapperName, unsupportedParam)
-		return fmt.Errorf("%w: %s takes %s", errUnsupportedParams, function.FuncName, unsupportedParam)
+		options.skipUnsupported(function, "", unsupportedParam)
+		return fmt.Errorf("%w: %s takes %s", ErrUnsupportedParams, function.FuncName, unsupportedParam)
+	}
+
+	// Record what we are about to emit.
+	r := newRecord(function, StatusNative)
+	r.Wrapper = wrapperName
+	if support == fillRequired {
+		r.Status = StatusFill
+	}
+	if ctorReplace.sig != nil {
+		r.Constructor = ctorReplace.f.Name()
 	}
+	options.record(r)
 
 	// Start emitting the wrapper function!
-	// Start with the func declaration and the start of f.Fuzz.
+	// Start with the func declaration, any seed corpus values from param hints or
+	// harvested from existing tests, and the start of f.Fuzz.
 	emit("func %s(f *testing.F) {\n", wrapperName)
+	if support == nativeSupport {
+		emitParamHints(emit, options.paramHints, paramSources, paramReprs)
+	}
+	emitSeeds(emit, options.seeds, paramSources, paramReprs, support, options.dictionary, localPkg)
 	emit("\tf.Fuzz(func(t *testing.T, ")
 
 	switch support {
@@ -239,7 +316,7 @@ func emitIndependentWrapper(emit emitFunc, function mod.Func, constructors []mod
 			emit("\t\tvar %s %s\n", p.paramName, p.typ)
 		}
 		// Third, create a fzgen.Fuzzer
-		emit("\t\tfz := fuzzer.NewFuzzer(data)\n")
+		emit("\t\tfz := %s\n", options.newFuzzerCall(dictVarName))
 		// Fourth, emit a potentially wide Fill call for all the variables we declared.
 		emit("\t\tfz.Fill(")
 		for i, p := range paramReprs {
@@ -275,10 +352,11 @@ func emitIndependentWrapper(emit emitFunc, function mod.Func, constructors []mod
 				emit(", err")
 			}
 			emit(" := ")
+			ctorTypeArgs := typeArgsList(ctorReplace.typeArgs, defaultQualifier)
 			if qualifyAll {
-				emit("%s.%s(", localPkg.Name(), ctorReplace.f.Name())
+				emit("%s.%s%s(", localPkg.Name(), ctorReplace.f.Name(), ctorTypeArgs)
 			} else {
-				emit("%s(", ctorReplace.f.Name())
+				emit("%s%s(", ctorReplace.f.Name(), ctorTypeArgs)
 			}
 			emitArgs(emit, ctorReplace.sig, 0, localPkg, inputParams)
 			emit(")\n")
@@ -298,7 +376,7 @@ func emitIndependentWrapper(emit emitFunc, function mod.Func, constructors []mod
 	}
 
 	// Emit the call to the wrapped function.
-	emitWrappedFunc(emit, f, wrappedSig, "", collisionOffset, qualifyAll, inputParams, localPkg)
+	emitWrappedFunc(emit, f, wrappedSig, function.TypeArgs, "", collisionOffset, qualifyAll, inputParams, localPkg)
 	emit("\t})\n")
 	emit("}\n\n")
 
@@ -337,8 +415,11 @@ func emitNilChecks(emit emitFunc, allParams []*types.Var, localPkg *types.Packag
 // A target that is not "" indicates the caller wants to use a
 // specific target name in place of any receiver name.
 // For example, a target set to "target" would result in "target.Load(key)".
-func emitWrappedFunc(emit emitFunc, f *types.Func, wrappedSig *types.Signature, target string, collisionOffset int, qualifyAll bool, allParams []*types.Var, localPkg *types.Package) {
+// typeArgs are the type arguments for an instantiated generic function, and are
+// not needed for a method because the receiver's type is already instantiated.
+func emitWrappedFunc(emit emitFunc, f *types.Func, wrappedSig *types.Signature, typeArgs []types.Type, target string, collisionOffset int, qualifyAll bool, allParams []*types.Var, localPkg *types.Package) {
 	recv := wrappedSig.Recv()
+	defaultQualifier, _ := qualifiers(localPkg, qualifyAll)
 	switch {
 	case recv != nil && target != "":
 		// Use target in place of the existing receiver, only doing this when we have a receiver.
@@ -348,9 +429,9 @@ func emitWrappedFunc(emit emitFunc, f *types.Func, wrappedSig *types.Signature,
 		recvName := avoidCollision(recv, 0, localPkg, allParams)
 		emit("\t%s.%s(", recvName, f.Name())
 	case qualifyAll:
-		emit("\t%s.%s(", localPkg.Name(), f.Name())
+		emit("\t%s.%s%s(", localPkg.Name(), f.Name(), typeArgsList(typeArgs, defaultQualifier))
 	default:
-		emit("\t%s(", f.Name())
+		emit("\t%s%s(", f.Name(), typeArgsList(typeArgs, defaultQualifier))
 	}
 	// emit the arguments to the wrapped function.
 	emitArgs(emit, wrappedSig, collisionOffset, localPkg, allParams)
@@ -473,6 +554,7 @@ type ctorMatch struct {
 	f                 *types.Func
 	ctorResultN       *types.Named // TODO: no longer need this, probably
 	secondResultIsErr bool
+	typeArgs          []types.Type // type arguments if f is an instantiated generic constructor
 }
 
 // constructorReplace determines if there is a constructor we can replace,
@@ -540,6 +622,7 @@ func constructorMatch(recv *types.Var, possibleCtor mod.Func) (ctorMatch, error)
 			f:                 possibleCtor.TypesFunc,
 			ctorResultN:       ctorResultN,
 			secondResultIsErr: secondResultIsErr,
+			typeArgs:          possibleCtor.TypeArgs,
 		}
 		return match, nil
 	}
@@ -565,7 +648,7 @@ func avoidCollision(v *types.Var, i int, localPkg *types.Package, allWrapperPara
 
 	collision := false
 	switch paramName {
-	case localPkg.Name(), "t", "f", "fz", "data", "target", "steps", "result1", "result2", "tmp1", "tmp2", "constraints":
+	case localPkg.Name(), "t", "f", "fz", "data", "target", "steps", "err", "result1", "result2", "tmp1", "tmp2", "encoded", "decoded", "ndst", "ref", "refErr", "refSteps", "fzRef", "constraints":
 		// avoid the common variable names for testing.T, testing.F, fzgen.Fuzzer,
 		// as well as variables we might emit (preferring an aesthetically pleasing
 		// name for something like "steps" in the common case over preserving
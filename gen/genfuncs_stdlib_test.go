@@ -54,7 +54,7 @@ func TestStrings(t *testing.T) {
 			if tt.onlyExported {
 				options |= flagRequireExported
 			}
-			pkgs, err := findFuncsGrouped(pkgPattern, ".", "^New", options)
+			pkgs, err := findFuncsGrouped([]string{pkgPattern}, ".", "^New", loadOptions{}, options)
 			if err != nil {
 				t.Fatalf("findFuncsGrouped() failed: %v", err)
 			}
@@ -40,7 +40,7 @@ func TestTypes(t *testing.T) {
 			if tt.onlyExported {
 				options |= flagRequireExported
 			}
-			pkgs, err := findFuncsGrouped(pkgPattern, ".", "^New", options)
+			pkgs, err := findFuncsGrouped([]string{pkgPattern}, ".", "^New", loadOptions{}, options)
 			if err != nil {
 				t.Fatalf("findFuncsGrouped() failed: %v", err)
 			}
@@ -83,6 +83,178 @@ func TestTypes(t *testing.T) {
 	}
 }
 
+// TestIndependentGolden checks emitIndependentWrappers against golden files for our example inputs.
+// To run just the generics tests:
+//    go test -run=TestIndependentGolden/generics
+func TestIndependentGolden(t *testing.T) {
+	tests := []struct {
+		name       string // Note: we use the test name also as the golden filename
+		pkgPattern string
+		opts       wrapperOptions
+		seeds      bool // harvest seeds from the package's seeds directory
+		dictionary bool // harvest a dictionary from the package
+	}{
+		{
+			name:       "generics_exported_not_local_pkg.go",
+			pkgPattern: "github.com/thepudds/fzgen/examples/inputs/test-generics",
+			opts:       wrapperOptions{qualifyAll: true},
+		},
+		{
+			name:       "generics_exported_local_pkg.go",
+			pkgPattern: "github.com/thepudds/fzgen/examples/inputs/test-generics",
+		},
+		{
+			name:       "generics_typeargs_exported_local_pkg.go",
+			pkgPattern: "github.com/thepudds/fzgen/examples/inputs/test-generics",
+			opts:       wrapperOptions{typeArgs: []string{"uint8", "[]string"}},
+		},
+		{
+			name:       "seeds_exported_not_local_pkg.go",
+			pkgPattern: "github.com/thepudds/fzgen/examples/inputs/test-seeds",
+			opts:       wrapperOptions{qualifyAll: true},
+			seeds:      true,
+		},
+		{
+			name:       "seeds_exported_local_pkg.go",
+			pkgPattern: "github.com/thepudds/fzgen/examples/inputs/test-seeds",
+			seeds:      true,
+		},
+		{
+			name:       "seeds_dict_exported_local_pkg.go",
+			pkgPattern: "github.com/thepudds/fzgen/examples/inputs/test-seeds",
+			seeds:      true,
+			dictionary: true,
+		},
+		{
+			name:       "inverse_exported_not_local_pkg.go",
+			pkgPattern: "github.com/thepudds/fzgen/examples/inputs/test-inverse",
+			opts:       wrapperOptions{qualifyAll: true},
+		},
+		{
+			name:       "inverse_exported_local_pkg.go",
+			pkgPattern: "github.com/thepudds/fzgen/examples/inputs/test-inverse",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pkg := findGoldenPkg(t, tt.pkgPattern, "^New")
+			wrapperOpts := tt.opts
+			wrapperOpts.insertConstructors = true
+			var err error
+			if tt.seeds {
+				wrapperOpts.seeds, err = harvestSeeds(pkg.functions[0].PkgDir, tt.pkgPattern, "seeds")
+				if err != nil {
+					t.Fatalf("harvestSeeds() failed: %v", err)
+				}
+			}
+			if tt.dictionary {
+				wrapperOpts.dictionary, err = harvestDictionary(pkg.functions[0].PkgDir, pkg.functions[0].TypesFunc.Pkg())
+				if err != nil {
+					t.Fatalf("harvestDictionary() failed: %v", err)
+				}
+			}
+
+			out, err := emitIndependentWrappers(tt.pkgPattern, pkg, "examplefuzz", wrapperOpts)
+			if err != nil {
+				t.Fatalf("emitIndependentWrappers() failed: %v", err)
+			}
+			checkGolden(t, tt.name, out, "emitIndependentWrappers")
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name       string // Note: we use the test name also as the golden filename
+		qualifyAll bool
+		chain      bool
+	}{
+		{
+			name:       "diff_exported_not_local_pkg.go",
+			qualifyAll: true,
+		},
+		{
+			name:       "diff_exported_local_pkg.go",
+			qualifyAll: false,
+		},
+		{
+			name:       "diff_chain_exported_not_local_pkg.go",
+			qualifyAll: true,
+			chain:      true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pkgPattern := "github.com/thepudds/fzgen/examples/inputs/test-diff"
+			pkg := findGoldenPkg(t, pkgPattern, "^New")
+			refPkg, err := loadDiffPkg(pkgPattern+"/sliceset", loadOptions{})
+			if err != nil {
+				t.Fatalf("loadDiffPkg() failed: %v", err)
+			}
+
+			wrapperOpts := wrapperOptions{
+				qualifyAll:         tt.qualifyAll,
+				insertConstructors: true,
+			}
+
+			out, err := emitDiffWrappers(pkgPattern, pkg, refPkg, "examplefuzz", tt.chain, wrapperOpts)
+			if err != nil {
+				t.Fatalf("emitDiffWrappers() failed: %v", err)
+			}
+			checkGolden(t, tt.name, out, "emitDiffWrappers")
+		})
+	}
+}
+
+// findGoldenPkg returns the exported functions matching funcPattern in pkgPattern,
+// which must match a single package.
+func findGoldenPkg(t *testing.T, pkgPattern, funcPattern string) *pkg {
+	t.Helper()
+	options := flagExcludeFuzzPrefix | flagMultiMatch | flagRequireExported
+	pkgs, err := findFuncsGrouped([]string{pkgPattern}, ".", funcPattern, loadOptions{}, options)
+	if err != nil {
+		t.Fatalf("findFuncsGrouped() failed: %v", err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("findFuncsGrouped() found unexpected pkgs count: %d", len(pkgs))
+	}
+	return pkgs[0]
+}
+
+// checkGolden formats out, which was returned by emitter, and compares it with the golden file
+// ../testdata/<name>. With -update, it first writes out to the golden file.
+func checkGolden(t *testing.T, name string, out []byte, emitter string) {
+	t.Helper()
+	out, err := imports.Process("autofuzz_test.go", out, nil)
+	if err != nil {
+		t.Fatalf("imports.Process() failed: %v", err)
+	}
+
+	got := string(out)
+	golden := filepath.Join("..", "testdata", name)
+	if *updateFlag {
+		// Note: using Fatalf above including so that we don't update if there was an earlier failure.
+		err = ioutil.WriteFile(golden, []byte(got), 0o644)
+		if err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+	b, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	want := string(b)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("%s() mismatch (-want +got):\n%s", emitter, diff)
+	}
+}
+
 // the simplest to run is:
 //    go test -run=ConstructorInjection/constructor_injection:_exported,_not_local_pkg
 
@@ -135,7 +307,7 @@ func TestConstructorInjection(t *testing.T) {
 			if tt.onlyExported {
 				options |= flagRequireExported
 			}
-			pkgs, err := findFuncsGrouped(pkgPattern, ".", "^New", options)
+			pkgs, err := findFuncsGrouped([]string{pkgPattern}, ".", "^New", loadOptions{}, options)
 			if err != nil {
 				t.Fatalf("findFuncsGrouped() failed: %v", err)
 			}
@@ -221,7 +393,7 @@ func TestExported(t *testing.T) {
 			if tt.onlyExported {
 				options |= flagRequireExported
 			}
-			pkgs, err := findFuncsGrouped(pkgPattern, ".", "^New", options)
+			pkgs, err := findFuncsGrouped([]string{pkgPattern}, ".", "^New", loadOptions{}, options)
 			if err != nil {
 				t.Fatalf("findFuncsGrouped() failed: %v", err)
 			}
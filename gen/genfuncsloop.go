@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/thepudds/fzgen/gen/internal/mod"
 )
@@ -18,54 +19,115 @@ import (
 // type such as interface{}.
 func emitChainWrappers(pkgPath string, pkgFuncs *pkg, wrapperPkgName string, options wrapperOptions) ([]byte, error) {
 	possibleConstructors := pkgFuncs.constructors
-	if len(possibleConstructors) == 0 {
-		return nil, errNoConstructorsMatch
+	if len(possibleConstructors) == 0 && !options.ctorFallbackEnabled() {
+		return nil, ErrNoConstructorsMatch
 	}
 
 	// Build a map from the receiver type to a set of possible constructors
 	// and possible steps with the same receiver type.
 	type chain struct {
 		recvType     string
+		recvN        *types.Named
 		constructors []mod.Func
 		steps        []mod.Func
 	}
 	recvTypes := make(map[string]*chain)
-	for _, function := range pkgFuncs.functions {
-		// recvN will be the named type if the receiver is a pointer receiver.
-		recvN := receiver(function.TypesFunc)
-		if recvN == nil {
+	// Put our functions in a deterministic order, sorting a copy so that we don't reorder our caller's slice.
+	functions := append([]mod.Func(nil), pkgFuncs.functions...)
+	sort.Slice(functions, func(i, j int) bool {
+		return functions[i].TypesFunc.String() < functions[j].TypesFunc.String()
+	})
+	var pkgLevelFuncs []mod.Func
+	for _, function := range functions {
+		if receiver(function.TypesFunc) == nil {
+			// Handled below, after we know the target types for our chains.
+			pkgLevelFuncs = append(pkgLevelFuncs, function)
+			continue
+		}
+		// A method on a generic type is expanded into one method per instantiation of the type,
+		// such that each instantiation (e.g., Set[int] and Set[string]) gets its own chain.
+		instantiated, err := instantiate(function, options.typeArgs)
+		if err != nil {
+			options.skip(function, err.Error())
 			continue
 		}
-		recvType := types.TypeString(recvN, nil)
-		c := recvTypes[recvType]
-		if c == nil {
-			c = &chain{recvType: recvType}
-			recvTypes[recvType] = c
+		for _, inst := range instantiated {
+			// recvN will be the named type if the receiver is a pointer receiver.
+			recvN := receiver(inst.TypesFunc)
+			if recvN == nil {
+				options.skip(inst, "not a method")
+				continue
+			}
+			recvType := types.TypeString(recvN, nil)
+			c := recvTypes[recvType]
+			if c == nil {
+				c = &chain{recvType: recvType, recvN: recvN}
+				recvTypes[recvType] = c
+			}
+			c.steps = append(c.steps, inst)
 		}
-		c.steps = append(c.steps, function)
 	}
 
 	if len(recvTypes) == 0 {
-		return nil, errNoMethodsMatch
+		return nil, ErrNoMethodsMatch
 	}
 
-	for _, constructor := range possibleConstructors {
-		if !isConstructor(constructor.TypesFunc) {
+	// A package-level function that takes a chain's target type as a parameter,
+	// such as Merge(a, b *Bitmap), is also a step in that chain, as is a package-level
+	// function that returns the target type, such as a constructor. The return values
+	// from those steps can be used as arguments to later steps, such as And(other *Bitmap).
+	for _, function := range pkgLevelFuncs {
+		instantiated, err := instantiate(function, options.typeArgs)
+		if err != nil {
+			options.skip(function, err.Error())
 			continue
 		}
-		// ctorResultN will be the named type if the returned type is a pointer to a named type.
-		ctorResultN, _ := constructorResult(constructor.TypesFunc)
-		if ctorResultN == nil {
-			// Not a named return result, so can't be a constructor.
-			continue
+		for _, inst := range instantiated {
+			sig, ok := inst.TypesFunc.Type().(*types.Signature)
+			if !ok {
+				continue
+			}
+			var added bool
+			for _, c := range recvTypes {
+				ct := chainTarget{named: c.recvN}
+				if ct.param(sig) >= 0 || ct.returns(sig) {
+					c.steps = append(c.steps, inst)
+					added = true
+				}
+			}
+			if !added {
+				options.skip(inst, "not a method and does not take or return a chain's target")
+			}
 		}
-		ctorType := types.TypeString(ctorResultN, nil)
-		c := recvTypes[ctorType]
-		if c == nil {
-			// No methods found in loop above for this named type, so nothing to do with this possible constructor.
-			continue
+	}
+
+	for _, c := range recvTypes {
+		// Track our steps so that we can also add constructors as steps without duplicates.
+		isStep := make(map[string]bool)
+		for _, step := range c.steps {
+			isStep[step.TypesFunc.String()] = true
+		}
+		// Generic constructors are instantiated to match this chain's receiver type, if possible.
+		for _, constructor := range instantiateConstructors(possibleConstructors, c.recvN) {
+			if !isConstructor(constructor.TypesFunc) {
+				continue
+			}
+			// ctorResultN will be the named type if the returned type is a pointer to a named type.
+			ctorResultN, _ := constructorResult(constructor.TypesFunc)
+			if ctorResultN == nil {
+				// Not a named return result, so can't be a constructor.
+				continue
+			}
+			if types.TypeString(ctorResultN, nil) != c.recvType {
+				// Not a constructor for this chain's named type.
+				continue
+			}
+			c.constructors = append(c.constructors, constructor)
+			if !isStep[constructor.TypesFunc.String()] {
+				c.steps = append(c.steps, constructor)
+				isStep[constructor.TypesFunc.String()] = true
+			}
 		}
-		c.constructors = append(c.constructors, constructor)
 	}
 
 	// Put our chains in a deterministic order.
@@ -85,8 +147,11 @@ func emitChainWrappers(pkgPath string, pkgFuncs *pkg, wrapperPkgName string, opt
 	}
 
 	// Emit the intro material
+	if options.buildConstraint != "" {
+		emit("%s\n\n", options.buildConstraint)
+	}
 	emit("package %s\n\n", wrapperPkgName)
-	emit(options.topComment)
+	emit("%s", options.topComment)
 	emit("import (\n")
 	emit("\t\"testing\"\n")
 	if options.qualifyAll {
@@ -100,15 +165,17 @@ func emitChainWrappers(pkgPath string, pkgFuncs *pkg, wrapperPkgName string, opt
 	var firstErr error
 	var success bool
 	for _, c := range chains {
-		if len(c.constructors) == 0 {
-			// No matching constructor.
-			// TODO: consider creating new object directly when there is no constructor
+		if len(c.constructors) == 0 && !options.ctorFallbackEnabled() {
+			// No matching constructor, and we were not asked to create the target without one.
+			for _, function := range c.steps {
+				options.skip(function, ErrNoConstructorsMatch.Error())
+			}
 			if firstErr == nil {
-				firstErr = errNoConstructorsMatch
+				firstErr = ErrNoConstructorsMatch
 			}
 			continue
 		}
-		err := emitChainWrapper(emit, c.steps, c.constructors, options)
+		err := emitChainWrapper(emit, c.steps, c.constructors, c.recvN, options)
 		if err != nil && firstErr == nil {
 			firstErr = err
 		}
@@ -120,32 +187,68 @@ func emitChainWrappers(pkgPath string, pkgFuncs *pkg, wrapperPkgName string, opt
 		return nil, firstErr
 	}
 
+	if !options.dictionary.empty() {
+		emitDictionary(emit, options.dictionary, chainDictVarName)
+	}
+
 	return buf.Bytes(), nil
 }
 
 // emitChainWrapper emits one fuzzing wrapper where possible for the list of functions passed in.
 // It might skip a function if it has no input parameters, or if it has a non-fuzzable parameter
-// type such as interface{}.
-func emitChainWrapper(emit emitFunc, functions []mod.Func, possibleConstructors []mod.Func, options wrapperOptions) error {
+// type such as interface{}. recvN is the chain's target type, which is created without a constructor
+// if there are no possibleConstructors and options.ctorFallback allows it.
+func emitChainWrapper(emit emitFunc, functions []mod.Func, possibleConstructors []mod.Func, recvN *types.Named, options wrapperOptions) error {
 	if len(functions) == 0 {
 		return errors.New("emitChainWrapper: zero functions")
 	}
-	if len(possibleConstructors) == 0 {
+	if len(possibleConstructors) == 0 && !options.ctorFallbackEnabled() {
 		return errors.New("emitChainWrapper: zero possible constructors")
 	}
 
-	// put possibleConstructors into a semi-deterministic order.
-	// TODO: for now, we'll prefer simpler constructors as approximated by length (so 'New' before 'NewSomething').
-	sort.Slice(possibleConstructors, func(i, j int) bool {
-		return len(possibleConstructors[i].FuncName) < len(possibleConstructors[j].FuncName)
-	})
-
-	// use the first constructor
-	ctor := possibleConstructors[0]
-	err := emitChainTarget(emit, ctor, options.qualifyAll)
-	if err != nil {
-		return fmt.Errorf("unable to create chain target for constructor %s: %w", ctor.FuncName, err)
+	// ctor is the zero mod.Func if we are creating the target without a constructor.
+	var ctor mod.Func
+	var chainName string
+	target := chainTarget{named: recvN}
+	if len(possibleConstructors) > 0 {
+		// put possibleConstructors into a semi-deterministic order.
+		// TODO: for now, we'll prefer simpler constructors as approximated by length (so 'New' before 'NewSomething').
+		sort.Slice(possibleConstructors, func(i, j int) bool {
+			return len(possibleConstructors[i].FuncName) < len(possibleConstructors[j].FuncName)
+		})
+
+		// Use each constructor with supported parameters and the same result type as the first one,
+		// which lets the fuzzer choose among them at run time. If there are none, we try the first
+		// constructor so that emitChainTarget reports the problem.
+		ctors := chainConstructors(possibleConstructors)
+		if len(ctors) == 0 {
+			ctors = possibleConstructors[:1]
+		}
+		ctor = ctors[0]
+		var err error
+		if len(ctors) == 1 {
+			chainName, err = emitChainTarget(emit, ctor, options)
+		} else {
+			chainName, err = emitChainTargetChoice(emit, ctors, options)
+		}
+		if err != nil {
+			for _, function := range functions {
+				r := newRecord(function, StatusSkipped)
+				r.Constructor = ctor.TypesFunc.Name()
+				r.Reason = fmt.Sprintf("unable to create chain target: %v", err)
+				options.record(r)
+			}
+			return fmt.Errorf("unable to create chain target for constructor %s: %w", ctor.FuncName, err)
+		}
+		target.ctor = ctor
+		for _, c := range ctors {
+			target.ctorNames = append(target.ctorNames, c.TypesFunc.Name())
+		}
+		_, target.pointer = ctor.TypesFunc.Type().(*types.Signature).Results().At(0).Type().(*types.Pointer)
+	} else {
+		chainName = emitChainFallbackTarget(emit, recvN, options)
 	}
+	target.name = chainName
 
 	// put our functions we want to wrap into a deterministic order
 	sort.Slice(functions, func(i, j int) bool {
@@ -158,15 +261,31 @@ func emitChainWrapper(emit emitFunc, functions []mod.Func, possibleConstructors
 
 	emit("\tsteps := []fuzzer.Step{\n")
 
+	// Hold the report records for our steps until we know whether the chain wrapper is emitted.
+	// If it is not, the steps are recorded as skipped with the chain's error.
+	var stepRecords []Record
+	stepOptions := options
+	stepOptions.report = func(r Record) { stepRecords = append(stepRecords, r) }
+	recordSteps := func(err error) {
+		for _, r := range stepRecords {
+			if err != nil && r.Status == StatusChain {
+				r.Status, r.Wrapper, r.Reason = StatusSkipped, "", fmt.Sprintf("chain %s not emitted: %v", chainName, err)
+			}
+			options.record(r)
+		}
+	}
+
 	// loop over our the functions we are wrapping, emitting a wrapper where possible.
 	var emittedSteps int
 	for _, function := range functions {
-		err := emitChainStep(emit, function, ctor, options.qualifyAll)
+		err := emitChainStep(emit, function, target, stepOptions)
 		if errors.Is(err, errSilentSkip) {
 			continue
 		}
 		if err != nil {
-			return fmt.Errorf("error processing %s: %v", function.FuncName, err)
+			err = fmt.Errorf("error processing %s: %v", function.FuncName, err)
+			recordSteps(err)
+			return err
 		}
 		emittedSteps++
 	}
@@ -181,88 +300,26 @@ func emitChainWrapper(emit emitFunc, functions []mod.Func, possibleConstructors
 		emit("\t})\n")
 		// close out test func
 		emit("}\n\n")
-		return errNoSteps
+		recordSteps(ErrNoSteps)
+		return ErrNoSteps
 	}
+	recordSteps(nil)
 
-	// emit the chain func
-	emit("\t// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain\n")
-	if !options.parallel {
-		emit("\tfz.Chain(steps)\n")
-	} else {
-		emit("\tfz.Chain(steps, fuzzer.ChainParallel)\n")
-	}
-
-	// possibly emit some roundtrip validation checks.
-	// TODO: move out to separate func.
-	// TODO: make this table-driven, nicer, and handle additional common roundtrip patterns.
-	var haveMarshalBinary, haveUnmarshalBinary, haveMarshalText, haveUnmarshalText bool
-	for _, flavor := range []string{"Binary", "Text"} {
-		for _, function := range functions {
-			implements, err := implementsEncodingMarshaler(function.TypesFunc, "Marshal"+flavor)
-			if err != nil {
-				fmt.Fprintln(os.Stderr, "fzgen: warning: continuing after failing to check encoding.Marshal capabilities:", err)
-				continue
-			}
-			if implements && flavor == "Binary" {
-				haveMarshalBinary = true
-			}
-			if implements && flavor == "Text" {
-				haveMarshalText = true
-			}
-			implements, err = implementsEncodingUnmarshaler(function.TypesFunc, "Unmarshal"+flavor)
-			if err != nil {
-				fmt.Fprintln(os.Stderr, "fzgen: warning: continuing after failing to check encoding.Unmarshal capabilities:", err)
-				continue
-			}
-			if implements && flavor == "Binary" {
-				haveUnmarshalBinary = true
-			}
-			if implements && flavor == "Text" {
-				haveUnmarshalText = true
-			}
-		}
+	// possibly emit a func to check the target's invariants after each step.
+	var chainOpts []string
+	if options.parallel {
+		chainOpts = append(chainOpts, "fuzzer.ChainParallel")
 	}
-	var doBinaryRoundtrip, doTextRoundtrip bool
-	if haveMarshalText && haveUnmarshalText {
-		doTextRoundtrip = true
-	}
-	if haveMarshalBinary && haveUnmarshalBinary {
-		doBinaryRoundtrip = true
+	if emitInvariant(emit, target, options) {
+		chainOpts = append(chainOpts, "fuzzer.ChainInvariant(invariant)")
 	}
 
-	var ctorTypeStringWithSelector string
-	if doBinaryRoundtrip || doTextRoundtrip {
-		emit("\n// Validate with some roundtrip checks. These can be edited or deleted if not appropriate for your target.")
+	// emit the chain func
+	emit("\t// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain\n")
+	emit("\tfz.Chain(%s)\n", strings.Join(append([]string{"steps"}, chainOpts...), ", "))
 
-		// Set up a qualifier so that we handle a local package vs. not for the temp variables
-		// for our target.
-		// TODO: make utility func, probably.
-		localPkg := ctor.TypesFunc.Pkg()
-		// Set up types.Qualifier funcs we can use with the types package
-		// to scope variables by a package or not.
-		defaultQualifier, _ := qualifiers(localPkg, options.qualifyAll)
-		ctorType := ctor.TypesFunc.Type()
-		ctorSig, ok := ctorType.(*types.Signature)
-		if !ok {
-			fmt.Fprintln(os.Stderr, "fzgen: warning: continuing after failing to determine type of target for roundtrip checks")
-			doBinaryRoundtrip = false
-			doTextRoundtrip = false
-		}
-		ctorResults := ctorSig.Results()
-		if ctorResults.Len() < 1 {
-			fmt.Fprintln(os.Stderr, "fzgen: warning: skipping roundtrip checks")
-			doBinaryRoundtrip = false
-			doTextRoundtrip = false
-		}
-		ctorResult := ctorResults.At(0)
-		ctorTypeStringWithSelector = types.TypeString(ctorResult.Type(), defaultQualifier)
-	}
-	if doTextRoundtrip {
-		emit(encodingTextMarshalerRoundtripTmpl, ctorTypeStringWithSelector)
-	}
-	if doBinaryRoundtrip {
-		emit(encodingBinaryMarshalerRoundtripTmpl, ctorTypeStringWithSelector)
-	}
+	// possibly emit some roundtrip validation checks.
+	emitRoundtrips(emit, target, options)
 
 	// close out the f.Fuzz func
 	emit("\t})\n")
@@ -273,11 +330,14 @@ func emitChainWrapper(emit emitFunc, functions []mod.Func, possibleConstructors
 	return nil
 }
 
-func emitChainTarget(emit emitFunc, function mod.Func, qualifyAll bool) error {
+// emitChainTarget emits the start of a chain wrapper, including creating the target via the constructor.
+// It returns the name of the chain wrapper.
+func emitChainTarget(emit emitFunc, function mod.Func, options wrapperOptions) (string, error) {
+	qualifyAll := options.qualifyAll
 	f := function.TypesFunc
 	wrappedSig, ok := f.Type().(*types.Signature)
 	if !ok {
-		return fmt.Errorf("function %s is not *types.Signature (%+v)", function, f)
+		return "", fmt.Errorf("function %s is not *types.Signature (%+v)", function, f)
 	}
 	localPkg := f.Pkg()
 
@@ -290,16 +350,20 @@ func emitChainTarget(emit emitFunc, function mod.Func, qualifyAll bool) error {
 
 	// Determine our wrapper name, which includes the receiver's type if we are wrapping a method.
 	var wrapperName string
-	if recv == nil {
+	switch {
+	case recv == nil && len(function.TypeArgs) > 0:
+		// An instantiated generic constructor, such as Fuzz_New_int_Chain.
+		wrapperName = fmt.Sprintf("Fuzz_%s_%s_Chain", f.Name(), typeArgsSuffix(function.TypeArgs, localQualifier))
+	case recv == nil:
 		wrapperName = fmt.Sprintf("Fuzz_%s_Chain", f.Name())
-	} else {
+	default:
 		n, err := namedType(recv)
 		if err != nil {
 			// output to stderr, but don't treat as fatal error.
 			fmt.Fprintf(os.Stderr, "fzgen: warning: createWrapper: failed to determine receiver type: %v: %v\n", recv, err)
-			return nil
+			return "", nil
 		}
-		recvNamedTypeLocalName := types.TypeString(n.Obj().Type(), localQualifier)
+		recvNamedTypeLocalName := recvTypeName(n, localQualifier)
 		wrapperName = fmt.Sprintf("Fuzz_%s_%s", recvNamedTypeLocalName, f.Name())
 	}
 
@@ -326,7 +390,7 @@ func emitChainTarget(emit emitFunc, function mod.Func, qualifyAll bool) error {
 	if support == noSupport {
 		// we can't emit this chain target.
 		emit("// skipping %s because parameters include func, chan, or unsupported interface: %v\n\n", wrapperName, unsupportedParam)
-		return fmt.Errorf("%w: %s takes %s", errUnsupportedParams, function.FuncName, unsupportedParam)
+		return "", fmt.Errorf("%w: %s takes %s", ErrUnsupportedParams, function.FuncName, unsupportedParam)
 	}
 
 	// Start emitting the wrapper function!
@@ -353,7 +417,7 @@ func emitChainTarget(emit emitFunc, function mod.Func, qualifyAll bool) error {
 			emit("\t\tvar %s %s\n", p.paramName, p.typ)
 		}
 		// Third, create a fzgen.Fuzzer
-		emit("\t\tfz := fuzzer.NewFuzzer(data)\n")
+		emit("\t\tfz := %s\n", options.newFuzzerCall(chainDictVarName))
 
 		// Fourth, emit a potentially wide Fill call for any input params for the constructor.
 		if len(inputParams) > 0 {
@@ -379,7 +443,7 @@ func emitChainTarget(emit emitFunc, function mod.Func, qualifyAll bool) error {
 	// we will reuse in our steps.
 	ctorResultN, returnsErr := constructorResult(f)
 	if ctorResultN == nil {
-		return fmt.Errorf("chain target constructor %s does not return named type (%+v)", f.Name(), f)
+		return "", fmt.Errorf("chain target constructor %s does not return named type (%+v)", f.Name(), f)
 	}
 	if returnsErr {
 		// TODO: instead of "target", would be nicer to reuse receiver variable name here (e.g., from first sample method).
@@ -388,21 +452,240 @@ func emitChainTarget(emit emitFunc, function mod.Func, qualifyAll bool) error {
 	} else {
 		emit("\ttarget := ")
 	}
-	emitWrappedFunc(emit, f, wrappedSig, "", 0, qualifyAll, inputParams, localPkg)
+	emitWrappedFunc(emit, f, wrappedSig, function.TypeArgs, "", 0, qualifyAll, inputParams, localPkg)
 	if returnsErr {
 		emit("\tif err != nil {\n")
 		emit("\t\treturn\n")
 		emit("\t}\n")
 	}
 	emit("\n")
-	return nil
+	return wrapperName, nil
+}
+
+// chainConstructors returns the constructors from the sorted possibleConstructors that
+// can be used to create a chain's target, which are those with supported parameters and
+// the same result type as the first such constructor.
+func chainConstructors(possibleConstructors []mod.Func) []mod.Func {
+	var result []mod.Func
+	var resultType types.Type
+	for _, ctor := range possibleConstructors {
+		sig, ok := ctor.TypesFunc.Type().(*types.Signature)
+		if !ok || sig.Results().Len() == 0 {
+			continue
+		}
+		if support, _ := checkParamSupport(params(ctor.TypesFunc)); support == noSupport {
+			continue
+		}
+		t := sig.Results().At(0).Type()
+		if resultType == nil {
+			resultType = t
+		} else if !types.Identical(t, resultType) {
+			continue
+		}
+		result = append(result, ctor)
+	}
+	return result
+}
+
+// emitChainTargetChoice emits the start of a chain wrapper that creates the target via one of
+// several constructors with the same result type, which is selected at run time via fz.Choose
+// so that each way of constructing the target is exercised. It returns the name of the chain wrapper,
+// which is based on the first constructor.
+func emitChainTargetChoice(emit emitFunc, ctors []mod.Func, options wrapperOptions) (string, error) {
+	qualifyAll := options.qualifyAll
+	first := ctors[0].TypesFunc
+	localPkg := first.Pkg()
+
+	// Set up types.Qualifier funcs we can use with the types package
+	// to scope variables by a package or not.
+	defaultQualifier, localQualifier := qualifiers(localPkg, qualifyAll)
+
+	// Determine our wrapper name, such as Fuzz_New_Chain or Fuzz_New_int_Chain.
+	wrapperName := fmt.Sprintf("Fuzz_%s_Chain", first.Name())
+	if len(ctors[0].TypeArgs) > 0 {
+		wrapperName = fmt.Sprintf("Fuzz_%s_%s_Chain", first.Name(), typeArgsSuffix(ctors[0].TypeArgs, localQualifier))
+	}
+
+	var anyReturnsErr bool
+	for _, ctor := range ctors {
+		ctorResultN, returnsErr := constructorResult(ctor.TypesFunc)
+		if ctorResultN == nil {
+			return "", fmt.Errorf("chain target constructor %s does not return named type (%+v)", ctor.TypesFunc.Name(), ctor.TypesFunc)
+		}
+		anyReturnsErr = anyReturnsErr || returnsErr
+	}
+	resultType := first.Type().(*types.Signature).Results().At(0).Type()
+
+	// Emit the func declaration and the start of f.Fuzz, followed by a switch that
+	// fills the arguments for the chosen constructor and calls it. The result will be similar to:
+	//    var target *Buffer
+	//    var err error
+	//    switch fz.Choose(2) {
+	//    case 0:
+	//      target = NewBuffer()
+	//    case 1:
+	//      var b []byte
+	//      fz.Fill(&b)
+	//      target, err = FromBytes(b)
+	//    }
+	//    if err != nil {
+	//      return
+	//    }
+	emit("func %s(f *testing.F) {\n", wrapperName)
+	emit("\tf.Fuzz(func(t *testing.T, data []byte) {\n")
+	emit("\t\tfz := %s\n\n", options.newFuzzerCall(chainDictVarName))
+	emit("\t\t// Create our target using one of the constructors, chosen by fz.Choose.\n")
+	emit("\t\tvar target %s\n", types.TypeString(resultType, defaultQualifier))
+	if anyReturnsErr {
+		emit("\t\tvar err error\n")
+	}
+	emit("\t\tswitch fz.Choose(%d) {\n", len(ctors))
+	for i, ctor := range ctors {
+		f := ctor.TypesFunc
+		wrappedSig := f.Type().(*types.Signature)
+		inputParams := params(f)
+		emit("\t\tcase %d:\n", i)
+		for j, v := range inputParams {
+			emit("\t\t\tvar %s %s\n", avoidCollision(v, j, localPkg, inputParams), types.TypeString(v.Type(), defaultQualifier))
+		}
+		if len(inputParams) > 0 {
+			emit("\t\t\tfz.Fill(")
+			for j, v := range inputParams {
+				if j > 0 {
+					emit(", ")
+				}
+				emit("&%s", avoidCollision(v, j, localPkg, inputParams))
+			}
+			emit(")\n")
+		}
+		emitNilChecks(emit, inputParams, localPkg)
+		if _, returnsErr := constructorResult(f); returnsErr {
+			emit("\t\t\ttarget, err = ")
+		} else {
+			emit("\t\t\ttarget = ")
+		}
+		emitWrappedFunc(emit, f, wrappedSig, ctor.TypeArgs, "", 0, qualifyAll, inputParams, localPkg)
+	}
+	emit("\t\t}\n")
+	if anyReturnsErr {
+		emit("\t\tif err != nil {\n")
+		emit("\t\t\treturn\n")
+		emit("\t\t}\n")
+	}
+	emit("\n")
+	return wrapperName, nil
+}
+
+// emitChainFallbackTarget emits the start of a chain wrapper for the named type recvN when there is
+// no matching constructor. The target is declared as a zero value, and is then populated via fz.Fill
+// if options.ctorFallback is ctorFallbackFill. It returns the name of the chain wrapper.
+func emitChainFallbackTarget(emit emitFunc, recvN *types.Named, options wrapperOptions) string {
+	localPkg := recvN.Obj().Pkg()
+
+	// Set up types.Qualifier funcs we can use with the types package
+	// to scope variables by a package or not.
+	defaultQualifier, localQualifier := qualifiers(localPkg, options.qualifyAll)
+
+	// Determine our wrapper name, such as Fuzz_Buffer_Chain, or Fuzz_Set_int_Chain for
+	// an instantiated generic type.
+	wrapperName := recvTypeName(recvN, localQualifier)
+	if recvN.TypeArgs().Len() > 0 {
+		var targs []types.Type
+		for i := 0; i < recvN.TypeArgs().Len(); i++ {
+			targs = append(targs, recvN.TypeArgs().At(i))
+		}
+		wrapperName += "_" + typeArgsSuffix(targs, localQualifier)
+	}
+	wrapperName = fmt.Sprintf("Fuzz_%s_Chain", wrapperName)
+
+	// Emit the func declaration and the start of f.Fuzz, followed by our target.
+	// The target is addressable, so both value and pointer methods can be called on it.
+	// The result will be similar to:
+	//    f.Fuzz(func(t *testing.T, data []byte) {
+	//      fz := fuzzer.NewFuzzer(data)
+	//
+	//      var target Buffer
+	//      fz.Fill(&target)
+	emit("func %s(f *testing.F) {\n", wrapperName)
+	emit("\tf.Fuzz(func(t *testing.T, data []byte) {\n")
+	emit("\t\tfz := %s\n\n", options.newFuzzerCall(chainDictVarName))
+	emit("\t\tvar target %s\n", types.TypeString(recvN, defaultQualifier))
+	if options.ctorFallback == ctorFallbackFill {
+		emit("\t\tfz.Fill(&target)\n")
+	}
+	emit("\n")
+	return wrapperName
+}
+
+// chainTarget describes the target of a chain, which is available to the steps as the 'target' variable.
+type chainTarget struct {
+	name    string       // name of the chain wrapper, such as Fuzz_NewMyType_Chain
+	named   *types.Named // named type of the target
+	pointer bool         // whether target is a pointer to the named type
+	ctor    mod.Func     // constructor used to create target, or the zero mod.Func if created without a constructor
+
+	ctorNames []string // names of all constructors the fuzzer can choose among to create target, used when reporting
+}
+
+// arg returns the expression for passing target as a parameter of type t,
+// where t is the named type of the target or a pointer to it.
+func (c chainTarget) arg(t types.Type) string {
+	_, isPtr := t.(*types.Pointer)
+	switch {
+	case isPtr == c.pointer:
+		return "target"
+	case isPtr:
+		return "&target"
+	default:
+		return "*target"
+	}
+}
+
+// param returns the index of the first parameter of sig that can be passed the target,
+// or -1 if there is none. A variadic parameter is not used.
+func (c chainTarget) param(sig *types.Signature) int {
+	for i := 0; i < sig.Params().Len(); i++ {
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			break
+		}
+		n, err := namedType(sig.Params().At(i))
+		if err == nil && types.Identical(n, c.named) {
+			return i
+		}
+	}
+	return -1
+}
+
+// returns reports if the first result of sig is the named type of the target or a pointer to it.
+func (c chainTarget) returns(sig *types.Signature) bool {
+	if sig.Results().Len() == 0 {
+		return false
+	}
+	n, err := namedType(sig.Results().At(0))
+	return err == nil && types.Identical(n, c.named)
+}
+
+// teardownMethods are names of functions that often end the useful life of a chain's target,
+// which emitChainStep gives a lower fuzzer.Step Weight if they have no parameters to fill.
+var teardownMethods = map[string]bool{
+	"Close":    true,
+	"Reset":    true,
+	"Clear":    true,
+	"Stop":     true,
+	"Shutdown": true,
 }
 
 // emitChainStep emits one fuzzing step if possible.
-// It takes a list of possible constructors to insert into the step body if the
-// constructor is suitable for creating the receiver of a wrapped method.
-// qualifyAll indicates if all variables should be qualified with their package.
-func emitChainStep(emit emitFunc, function mod.Func, constructor mod.Func, qualifyAll bool) error {
+// The step is either a method on the chain's target, or a package-level function
+// that takes the target as one of its parameters, in which case target is passed
+// for that parameter and the remaining parameters are filled by fz.Chain.
+// A package-level function that instead returns the target type, such as a constructor,
+// is also a step, which allows its return value to be used as an argument in later steps.
+// options.qualifyAll indicates if all variables should be qualified with their package.
+func emitChainStep(emit emitFunc, function mod.Func, target chainTarget, options wrapperOptions) error {
+	qualifyAll := options.qualifyAll
+	constructor := target.ctor
+	chainName := target.name
 	f := function.TypesFunc
 	wrappedSig, ok := f.Type().(*types.Signature)
 	if !ok {
@@ -419,30 +702,41 @@ func emitChainStep(emit emitFunc, function mod.Func, constructor mod.Func, quali
 
 	// Determine our wrapper name, which includes the receiver's type if we are wrapping a method,
 	// as well as see if the receiver matches the type of our constructor.
+	// For a package-level function, we instead find which parameter will be our target.
 	var wrapperName string
+	targetParam := -1
 	if recv == nil {
-		// TODO: could optionally include even if no receiver. For example, uuid.SetNodeID() changes global state, I think.
-		// TODO: could include constructors or other funcs that return the chain's targeted type.
-		// With return value chaining, this would support creating the ip2 in Addr.Less(ip2 Addr) in netip.
-		// If we restore this branch, set wrapperName = fmt.Sprintf("Fuzz_%s", f.Name())
-		return errSilentSkip
+		// TODO: could optionally include even if the target is not a parameter. For example, uuid.SetNodeID() changes global state, I think.
+		targetParam = target.param(wrappedSig)
+		if targetParam < 0 && !target.returns(wrappedSig) {
+			options.skip(function, "not a method and does not take or return the chain's target")
+			return errSilentSkip
+		}
+		wrapperName = fmt.Sprintf("Fuzz_%s", f.Name())
+		if len(function.TypeArgs) > 0 {
+			wrapperName = fmt.Sprintf("%s_%s", wrapperName, typeArgsSuffix(function.TypeArgs, localQualifier))
+		}
 	} else {
 		n, err := namedType(recv)
 		if err != nil {
 			// output to stderr, but don't treat as fatal error.
 			fmt.Fprintf(os.Stderr, "fzgen: warning: createWrapper: failed to determine receiver type: %v: %v\n", recv, err)
+			options.skip(function, fmt.Sprintf("failed to determine receiver type: %v", err))
 			return nil
 		}
-		// Check if we have a constructor that works.
-		ctorMatch, err := constructorMatch(recv, constructor)
-		if err != nil {
-			return fmt.Errorf("genfuncsloop: error when looking for matching constructor: %v", err)
-		}
-		if ctorMatch.sig == nil {
-			return errSilentSkip
+		// Check if we have a constructor that works, unless we created the target without a constructor.
+		if constructor.TypesFunc != nil {
+			ctorMatch, err := constructorMatch(recv, constructor)
+			if err != nil {
+				return fmt.Errorf("genfuncsloop: error when looking for matching constructor: %v", err)
+			}
+			if ctorMatch.sig == nil {
+				options.skip(function, ErrNoConstructorsMatch.Error())
+				return errSilentSkip
+			}
 		}
 
-		recvNamedTypeLocalName := types.TypeString(n.Obj().Type(), localQualifier)
+		recvNamedTypeLocalName := recvTypeName(n, localQualifier)
 		wrapperName = fmt.Sprintf("Fuzz_%s_%s", recvNamedTypeLocalName, f.Name())
 	}
 
@@ -466,14 +760,26 @@ func emitChainStep(emit emitFunc, function mod.Func, constructor mod.Func, quali
 	}
 
 	// Check if we have an interface or function pointer in our desired parameters,
-	// which we can't fill with values during fuzzing.
-	support, unsupportedParam := checkParamSupport(inputParams)
+	// which we can't fill with values during fuzzing. Our target is not filled, so it is not checked.
+	var filledParams []*types.Var
+	for i, v := range inputParams {
+		if i != targetParam {
+			filledParams = append(filledParams, v)
+		}
+	}
+	support, unsupportedParam := checkParamSupport(filledParams)
 	if support == noSupport {
 		// skip this wrapper.
 		emit("// skipping %s because parameters include func, chan, or unsupported interface: %v\n\n", wrapperName, unsupportedParam)
+		options.skipUnsupported(function, chainName, unsupportedParam)
 		return errSilentSkip
 	}
 
+	r := newRecord(function, StatusChain)
+	r.Wrapper = chainName
+	r.Constructor = strings.Join(target.ctorNames, ",")
+	options.record(r)
+
 	// Start emitting the wrapper function, inside of a fzgen/fuzzer.Step. Will be similar to:
 	//   Step{
 	// 	   Name: "input int",
@@ -483,6 +789,10 @@ func emitChainStep(emit emitFunc, function mod.Func, constructor mod.Func, quali
 	//   },
 	emit("\t{\n")
 	emit("\t\tName: \"%s\",\n", wrapperName)
+	if teardownMethods[f.Name()] && len(filledParams) == 0 {
+		emit("\t\t// %s is chosen less often than steps with the default Weight of 4, so that more steps run before it.\n", f.Name())
+		emit("\t\tWeight: 1,\n")
+	}
 	emit("\t\tFunc: func(")
 
 	switch support {
@@ -499,20 +809,26 @@ func emitChainStep(emit emitFunc, function mod.Func, constructor mod.Func, quali
 		//
 		// The result for this line will end up similar to:
 		//    Func: func(s string, i *int) {
-		// Iterate over the our input parameters and emit.
+		// Iterate over the our input parameters and emit, skipping any parameter for our target.
+		var emitted int
 		for i, p := range paramReprs {
+			if i == targetParam {
+				continue
+			}
 			// want: foo string, bar int
-			if i > 0 {
+			if emitted > 0 {
 				// need a comma if something has already been emitted
 				emit(", ")
 			}
 			emit("%s %s", p.paramName, p.typ)
+			emitted++
 		}
 		emit(") ")
 
-		// TODO: centralize error check logic
+		// We keep all results, including a trailing error, which fz.Chain
+		// observes in order to apply any error handling set via a ChainOpt.
 		results := wrappedSig.Results()
-		if results.Len() > 0 && !(results.Len() == 1 && results.At(0).Type().String() == "error") {
+		if results.Len() > 0 {
 			emit("(") // goimports should clean up paren if it is not needed
 			for i := 0; i < results.Len(); i++ {
 				if i > 0 {
@@ -536,137 +852,37 @@ func emitChainStep(emit emitFunc, function mod.Func, constructor mod.Func, quali
 	// collisionOffset is 0 because we do not have a constructor within this function
 	// literal we are creating and hence we don't need to worry about calculating
 	// a collisionOffset.
-	// Include a 'return' if we have a non-error return value for our wrapped func.
-	results := wrappedSig.Results()
-	if results.Len() > 0 && !(results.Len() == 1 && results.At(0).Type().String() == "error") {
+	// Include a 'return' if we have any return values for our wrapped func.
+	if wrappedSig.Results().Len() > 0 {
 		emit("\treturn ")
 	}
-	emitWrappedFunc(emit, f, wrappedSig, "target", 0, qualifyAll, inputParams, localPkg)
+	if recv != nil {
+		emitWrappedFunc(emit, f, wrappedSig, nil, "target", 0, qualifyAll, inputParams, localPkg)
+	} else {
+		// Call the package-level function, passing our target in place of its parameter.
+		if qualifyAll {
+			emit("\t%s.%s%s(", localPkg.Name(), f.Name(), typeArgsList(function.TypeArgs, defaultQualifier))
+		} else {
+			emit("\t%s%s(", f.Name(), typeArgsList(function.TypeArgs, defaultQualifier))
+		}
+		for i, p := range paramReprs {
+			if i > 0 {
+				emit(", ")
+			}
+			if i == targetParam {
+				emit(target.arg(p.v.Type()))
+			} else {
+				emit(p.paramName)
+			}
+		}
+		if wrappedSig.Variadic() {
+			emit("...")
+		}
+		emit(")\n")
+	}
 
 	// close out the func as well as the Step struct
 	emit("\t\t},\n")
 	emit("\t},\n")
 	return nil
 }
-
-// implementsEncodingMarshaler reports if f implements encoding.BinaryMarshaler or encoding.TextMarshaler.
-// TODO: this is a bit quick. replace with table driven check that handles other common roundrip interfaces & patterns.
-func implementsEncodingMarshaler(f *types.Func, desiredName string) (bool, error) {
-	sig, ok := f.Type().(*types.Signature)
-	if !ok {
-		return false, fmt.Errorf("func %s is not *types.Signature (%+v)", f.Name(), f)
-	}
-
-	if f.Name() != desiredName {
-		return false, nil
-	}
-
-	// check params
-	params := sig.Params()
-	if params.Len() != 0 {
-		return false, nil
-	}
-
-	// check return values
-	ctorResults := sig.Results()
-	if ctorResults.Len() != 2 {
-		return false, nil
-	}
-
-	// check if first return type is []byte.
-	firstResult := ctorResults.At(0)
-	if firstResult.Type().String() != "[]byte" && firstResult.Type().String() != "[]uint8" {
-		return false, nil
-	}
-
-	// check if second return type is error type.
-	secondResult := ctorResults.At(1)
-	_, ok = secondResult.Type().Underlying().(*types.Interface)
-	if !ok || secondResult.Type().String() != "error" {
-		return false, nil
-	}
-
-	return true, nil
-}
-
-// implementsEncodingUnmarshaler reports if f implements encoding.BinaryUnmarshaler or encoding.TextUnmarshaler.
-// TODO: this is a bit quick. replace with table driven check that handles other common roundrip interfaces & patterns.
-func implementsEncodingUnmarshaler(f *types.Func, desiredName string) (bool, error) {
-	sig, ok := f.Type().(*types.Signature)
-	if !ok {
-		return false, fmt.Errorf("func %s is not *types.Signature (%+v)", f.Name(), f)
-	}
-
-	if f.Name() != desiredName {
-		return false, nil
-	}
-
-	// check params
-	params := sig.Params()
-	if params.Len() != 1 {
-		return false, nil
-	}
-
-	// check if only param is []byte.
-	param := params.At(0)
-	if param.Type().String() != "[]byte" && param.Type().String() != "[]uint8" {
-		return false, nil
-	}
-
-	// check return values
-	ctorResults := sig.Results()
-	if ctorResults.Len() != 1 {
-		return false, nil
-	}
-
-	// check if only return type is error type.
-	secondResult := ctorResults.At(0)
-	_, ok = secondResult.Type().Underlying().(*types.Interface)
-	if !ok || secondResult.Type().String() != "error" {
-		return false, nil
-	}
-
-	return true, nil
-}
-
-// TODO: make template, and make table driven to handle additional common patterns.
-var encodingTextMarshalerRoundtripTmpl string = `
-		// Check MarshalText.
-		result1, err := target.MarshalText()
-		if err != nil {
-				// Some targets should never return an error here for an object created by a constructor.
-				// If that is the case for your target, you can change this to a panic(err) or t.Fatal.
-				return
-		}
-
-		// Check UnmarshalText.
-		var tmp1 %s
-		err = tmp1.UnmarshalText(result1)
-		if err != nil {
-			panic(fmt.Sprintf("UnmarshalText failed after successful MarshalText. original: %%v marshalled: %%q error: %%v", target, result1, err))
-		}
-		if !reflect.DeepEqual(target, tmp1) {
-			panic(fmt.Sprintf("MarshalText/UnmarshalText roundtrip equality failed. original: %%v  marshalled: %%q unmarshalled: %%v", target, result1, tmp1))
-		}
-`
-
-var encodingBinaryMarshalerRoundtripTmpl string = `
-		// Check MarshalBinary.
-		result2, err := target.MarshalBinary()
-		if err != nil {
-				// Some targets should never return an error here for an object created by a constructor.
-				// If that is the case for your target, you can change this to a panic(err) or t.Fatal.
-				return
-		}
-
-		// Check UnmarshalBinary.
-		var tmp2 %s
-		err = tmp2.UnmarshalBinary(result2)
-		if err != nil {
-			panic(fmt.Sprintf("UnmarshalBinary failed after successful MarshalBinary. original: %%v %%#v marshalled: %%q error: %%v", target, target, result2, err))
-		}
-		if !reflect.DeepEqual(target, tmp2) {
-			panic(fmt.Sprintf("MarshalBinary/UnmarshalBinary roundtrip equality failed. original: %%v %%#v marshalled: %%q unmarshalled: %%v %%#v",
-				  target, target, result2, tmp2, tmp2))
-		}
-`
@@ -45,7 +45,7 @@ func TestChainRace(t *testing.T) {
 			if tt.onlyExported {
 				options |= flagRequireExported
 			}
-			pkgs, err := findFuncsGrouped(pkgPattern, ".", "^New", options)
+			pkgs, err := findFuncsGrouped([]string{pkgPattern}, ".", "^New", loadOptions{}, options)
 			if err != nil {
 				t.Fatalf("findFuncsGrouped() failed: %v", err)
 			}
@@ -119,7 +119,7 @@ func TestChainUUID(t *testing.T) {
 			if tt.onlyExported {
 				options |= flagRequireExported
 			}
-			pkgs, err := findFuncsGrouped(pkgPattern, ".", "^New", options)
+			pkgs, err := findFuncsGrouped([]string{pkgPattern}, ".", "^New", loadOptions{}, options)
 			if err != nil {
 				t.Fatalf("findFuncsGrouped() failed: %v", err)
 			}
@@ -187,7 +187,7 @@ func TestChainNilChecks(t *testing.T) {
 			if tt.onlyExported {
 				options |= flagRequireExported
 			}
-			pkgs, err := findFuncsGrouped(pkgPattern, ".", "^New", options)
+			pkgs, err := findFuncsGrouped([]string{pkgPattern}, ".", "^New", loadOptions{}, options)
 			if err != nil {
 				t.Fatalf("findFuncsGrouped() failed: %v", err)
 			}
@@ -230,3 +230,105 @@ func TestChainNilChecks(t *testing.T) {
 		})
 	}
 }
+
+// TestChainGolden checks emitChainWrappers against golden files for our example inputs.
+// To run just one:
+//    go test -run=ChainGolden/chain_weights.go
+func TestChainGolden(t *testing.T) {
+	tests := []struct {
+		name        string // Note: we use the test name also as the golden filename
+		pkgPattern  string
+		funcPattern string
+		opts        wrapperOptions
+	}{
+		{
+			name:        "generics_chain_exported_not_local_pkg.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-generics",
+			funcPattern: "^New",
+			opts:        wrapperOptions{qualifyAll: true, parallel: true},
+		},
+		{
+			name:        "generics_chain_exported_local_pkg.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-generics",
+			funcPattern: "^New",
+		},
+		{
+			name:        "ctor_fallback_zero_exported_not_local_pkg.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-chain-fallback",
+			funcPattern: "^New",
+			opts:        wrapperOptions{qualifyAll: true, ctorFallback: ctorFallbackZero},
+		},
+		{
+			name:        "ctor_fallback_fill_exported_local_pkg.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-chain-fallback",
+			funcPattern: "^New",
+			opts:        wrapperOptions{ctorFallback: ctorFallbackFill},
+		},
+		{
+			name:        "chain_funcs_exported_not_local_pkg.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-chain-funcs",
+			funcPattern: "^New",
+			opts:        wrapperOptions{qualifyAll: true},
+		},
+		{
+			name:        "chain_funcs_exported_local_pkg.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-chain-funcs",
+			funcPattern: "^New",
+		},
+		{
+			name:        "chain_ctors_exported_not_local_pkg.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-chain-ctors",
+			funcPattern: ".",
+			opts:        wrapperOptions{qualifyAll: true},
+		},
+		{
+			name:        "chain_ctors_exported_local_pkg.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-chain-ctors",
+			funcPattern: ".",
+		},
+		{
+			name:        "chain_roundtrip_exported_not_local_pkg.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-chain-roundtrip",
+			funcPattern: "^New",
+			opts:        wrapperOptions{qualifyAll: true},
+		},
+		{
+			name:        "chain_roundtrip_exported_local_pkg.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-chain-roundtrip",
+			funcPattern: "^New",
+		},
+		{
+			name:        "chain_invariants_default.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-chain-invariants",
+			funcPattern: "^New",
+			opts:        wrapperOptions{invariants: splitInvariants("")},
+		},
+		{
+			name:        "chain_invariants_custom_parallel.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-chain-invariants",
+			funcPattern: "^New",
+			opts:        wrapperOptions{parallel: true, invariants: splitInvariants("Sorted,Len")},
+		},
+		{
+			name:        "chain_weights.go",
+			pkgPattern:  "github.com/thepudds/fzgen/examples/inputs/test-chain-weights",
+			funcPattern: "^New",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pkg := findGoldenPkg(t, tt.pkgPattern, tt.funcPattern)
+			wrapperOpts := tt.opts
+			wrapperOpts.insertConstructors = true
+
+			out, err := emitChainWrappers(tt.pkgPattern, pkg, "examplefuzz", wrapperOpts)
+			if err != nil {
+				t.Fatalf("emitChainWrappers() failed: %v", err)
+			}
+			checkGolden(t, tt.name, out, "emitChainWrappers")
+		})
+	}
+}
@@ -8,10 +8,11 @@ import (
 // Func represents a discovered function that will be fuzzed.
 type Func struct {
 	FuncName  string
-	PkgName   string      // package name (should be the same as the package's package statement)
-	PkgPath   string      // import path
-	PkgDir    string      // local on-disk directory
-	TypesFunc *types.Func // auxiliary information about a Func from the go/types package
+	PkgName   string       // package name (should be the same as the package's package statement)
+	PkgPath   string       // import path
+	PkgDir    string       // local on-disk directory
+	TypesFunc *types.Func  // auxiliary information about a Func from the go/types package
+	TypeArgs  []types.Type // concrete type arguments if TypesFunc is an instantiated generic func or method
 }
 
 // FuzzName returns the '<pkg>.<OrigFuzzFunc>' string.
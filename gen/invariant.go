@@ -0,0 +1,95 @@
+package gen
+
+import (
+	"go/types"
+	"strings"
+)
+
+// defaultInvariants is the default list of names of methods that check the consistency of a chain's target.
+const defaultInvariants = "Validate,CheckInvariants,Len"
+
+// invariantsNone disables invariant checks.
+const invariantsNone = "none"
+
+// splitInvariants splits a comma-separated list of method names, such as "Validate,Len".
+// An empty string means defaultInvariants, and "none" means no names.
+func splitInvariants(s string) []string {
+	if s == "" {
+		s = defaultInvariants
+	}
+	if s == invariantsNone {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// invariantMethods returns the methods of named listed in names that can be used to check its consistency,
+// which are methods without parameters that return nothing (and presumably panic on failure),
+// an error, a bool that is true on success, or an int such as a length that must not be negative.
+func invariantMethods(named *types.Named, names []string) []string {
+	var found []string
+	for _, name := range names {
+		sig := method(named, name)
+		if sig == nil || sig.Params().Len() != 0 || sig.Results().Len() > 1 {
+			continue
+		}
+		if sig.Results().Len() == 1 {
+			r := sig.Results().At(0).Type()
+			if !types.Identical(r, errorType) && !types.Identical(r, types.Typ[types.Bool]) && !types.Identical(r, types.Typ[types.Int]) {
+				continue
+			}
+		}
+		found = append(found, name)
+	}
+	return found
+}
+
+// emitInvariant emits a func that calls the invariant methods of the chain's target, which is
+// passed to fz.Chain via fuzzer.ChainInvariant. It reports whether it emitted anything.
+// The result will be similar to:
+//    // Check the target's invariants after each step. This can be edited or deleted if not appropriate for your target.
+//    invariant := func() error {
+//        if err := target.Validate(); err != nil {
+//            return err
+//        }
+//        if n := target.Len(); n < 0 {
+//            return fmt.Errorf("Len() = %d, want >= 0", n)
+//        }
+//        return nil
+//    }
+func emitInvariant(emit emitFunc, target chainTarget, options wrapperOptions) bool {
+	methods := invariantMethods(target.named, options.invariants)
+	if len(methods) == 0 {
+		return false
+	}
+	emit("\t// Check the target's invariants after each step. This can be edited or deleted if not appropriate for your target.\n")
+	emit("\tinvariant := func() error {\n")
+	for _, name := range methods {
+		sig := method(target.named, name)
+		if sig.Results().Len() == 0 {
+			emit("\t\ttarget.%s()\n", name)
+			continue
+		}
+		switch r := sig.Results().At(0).Type(); {
+		case types.Identical(r, errorType):
+			emit("\t\tif err := target.%s(); err != nil {\n", name)
+			emit("\t\t\treturn err\n")
+		case types.Identical(r, types.Typ[types.Bool]):
+			emit("\t\tif !target.%s() {\n", name)
+			emit("\t\t\treturn errors.New(\"%s() = false\")\n", name)
+		default:
+			emit("\t\tif n := target.%s(); n < 0 {\n", name)
+			emit("\t\t\treturn fmt.Errorf(\"%s() = %%d, want >= 0\", n)\n", name)
+		}
+		emit("\t\t}\n")
+	}
+	emit("\t\treturn nil\n")
+	emit("\t}\n\n")
+	return true
+}
@@ -0,0 +1,276 @@
+package gen
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/thepudds/fzgen/gen/internal/mod"
+)
+
+// inverseWords lists the words that indicate a package-level function has an inverse,
+// such as Quote and Unquote, or QueryEscape and QueryUnescape.
+var inverseWords = []struct{ encode, decode string }{
+	{"Encode", "Decode"},
+	{"Marshal", "Unmarshal"},
+	{"Compress", "Decompress"},
+	{"Quote", "Unquote"},
+	{"Format", "Parse"},
+	{"Escape", "Unescape"},
+}
+
+// inverseKind describes the signatures of a pair of inverse functions.
+type inverseKind int
+
+const (
+	// valueInverse is a pair like Quote(s string) string and Unquote(s string) (string, error).
+	// Either result can optionally include an error.
+	valueInverse inverseKind = iota
+	// outParamInverse is a pair like Marshal(v T) ([]byte, error) and Unmarshal(b []byte, v *T) error.
+	outParamInverse
+	// dstInverse is a pair like Encode(dst, src []byte) int and Decode(dst, src []byte, flush bool) (ndst, nsrc int, err error)
+	// from encoding/ascii85, where the package also has MaxEncodedLen(n int) int or EncodedLen(n int) int.
+	dstInverse
+)
+
+// inverse is a pair of package-level functions where decode should undo encode.
+type inverse struct {
+	kind       inverseKind
+	encode     *types.Func
+	decode     *types.Func
+	encodedLen *types.Func // for dstInverse, the function that returns the size of the encode's dst
+}
+
+// findInverse reports whether f has an inverse function in its package, based on the name and signature.
+func findInverse(f *types.Func) (inverse, bool) {
+	sig := f.Type().(*types.Signature)
+	if sig.Recv() != nil || sig.TypeParams().Len() > 0 || sig.Variadic() {
+		return inverse{}, false
+	}
+	for _, w := range inverseWords {
+		if !strings.Contains(f.Name(), w.encode) {
+			continue
+		}
+		name := strings.Replace(f.Name(), w.encode, w.decode, 1)
+		decode, ok := f.Pkg().Scope().Lookup(name).(*types.Func)
+		if !ok || !decode.Exported() {
+			continue
+		}
+		inv := inverse{encode: f, decode: decode}
+		for _, kind := range []inverseKind{valueInverse, outParamInverse, dstInverse} {
+			inv.kind = kind
+			if inv.matches() {
+				return inv, true
+			}
+		}
+	}
+	return inverse{}, false
+}
+
+// matches reports whether the signatures of inv.encode and inv.decode match inv.kind.
+// For a dstInverse, it also sets inv.encodedLen.
+func (inv *inverse) matches() bool {
+	enc := inv.encode.Type().(*types.Signature)
+	dec := inv.decode.Type().(*types.Signature)
+	if dec.TypeParams().Len() > 0 || dec.Variadic() {
+		return false
+	}
+	encResults, encErr := resultsWithoutError(enc)
+	decResults, decErr := resultsWithoutError(dec)
+
+	switch inv.kind {
+	case valueInverse:
+		// Encode(x T) R and Decode(r R) T, with optional errors.
+		return enc.Params().Len() == 1 && len(encResults) == 1 &&
+			dec.Params().Len() == 1 && len(decResults) == 1 &&
+			types.Identical(encResults[0], dec.Params().At(0).Type()) &&
+			types.Identical(decResults[0], enc.Params().At(0).Type())
+	case outParamInverse:
+		// Encode(x T) R and Decode(r R, x *T) error, with an optional error for Encode.
+		if enc.Params().Len() != 1 || len(encResults) != 1 || dec.Params().Len() != 2 || len(decResults) != 0 || !decErr {
+			return false
+		}
+		ptr, ok := dec.Params().At(1).Type().(*types.Pointer)
+		return ok && types.Identical(encResults[0], dec.Params().At(0).Type()) &&
+			types.Identical(ptr.Elem(), enc.Params().At(0).Type())
+	case dstInverse:
+		// Encode(dst, src []byte) int and Decode(dst, src []byte[, flush bool]) (ndst int, [nsrc int,] err error).
+		if !matches(enc, []types.Type{byteSliceType, byteSliceType}, []types.Type{types.Typ[types.Int]}) || encErr {
+			return false
+		}
+		switch {
+		case dec.Params().Len() == 2:
+		case dec.Params().Len() == 3 && types.Identical(dec.Params().At(2).Type(), types.Typ[types.Bool]):
+		default:
+			return false
+		}
+		for i := 0; i < 2; i++ {
+			if !types.Identical(dec.Params().At(i).Type(), byteSliceType) {
+				return false
+			}
+		}
+		if !decErr || len(decResults) == 0 || len(decResults) > 2 {
+			return false
+		}
+		for _, r := range decResults {
+			if !types.Identical(r, types.Typ[types.Int]) {
+				return false
+			}
+		}
+		for _, name := range []string{"MaxEncodedLen", "EncodedLen"} {
+			f, ok := inv.encode.Pkg().Scope().Lookup(name).(*types.Func)
+			if ok && f.Exported() && matches(f.Type().(*types.Signature), []types.Type{types.Typ[types.Int]}, []types.Type{types.Typ[types.Int]}) {
+				inv.encodedLen = f
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resultsWithoutError returns the result types of sig other than a final error,
+// and whether there is a final error.
+func resultsWithoutError(sig *types.Signature) ([]types.Type, bool) {
+	var results []types.Type
+	for i := 0; i < sig.Results().Len(); i++ {
+		results = append(results, sig.Results().At(i).Type())
+	}
+	if len(results) > 0 && types.Identical(results[len(results)-1], errorType) {
+		return results[:len(results)-1], true
+	}
+	return results, false
+}
+
+// emitInverseWrapper emits a wrapper that checks that inv.decode undoes inv.encode,
+// and panics with the original, encoded, and decoded values if not.
+// An error from the encode function is not considered a failure, but an error from the decode
+// function after a successful encode is.
+func emitInverseWrapper(emit emitFunc, inv inverse, options wrapperOptions) error {
+	encName, decName := inv.encode.Name(), inv.decode.Name()
+	wrapperName := fmt.Sprintf("Fuzz_%s_%s_Roundtrip", encName, decName)
+	localPkg := inv.encode.Pkg()
+	defaultQualifier, _ := qualifiers(localPkg, options.qualifyAll)
+	qualified := func(f *types.Func) string {
+		if options.qualifyAll {
+			return localPkg.Name() + "." + f.Name()
+		}
+		return f.Name()
+	}
+
+	encSig := inv.encode.Type().(*types.Signature)
+	decSig := inv.decode.Type().(*types.Signature)
+	_, encErr := resultsWithoutError(encSig)
+
+	// Our input is the parameter of the encode function, or its src parameter for a dstInverse.
+	input := encSig.Params().At(0)
+	if inv.kind == dstInverse {
+		input = encSig.Params().At(1)
+	}
+	inputParams := []*types.Var{input}
+	name := avoidCollision(input, 0, localPkg, inputParams)
+	typ := types.TypeString(input.Type(), defaultQualifier)
+	support, unsupportedParam := checkParamSupport(inputParams)
+	if support == noSupport {
+		emit("// skipping %s because parameters include func, chan, or unsupported interface: %v\n\n", wrapperName, unsupportedParam)
+		return fmt.Errorf("%w: %s takes %s", ErrUnsupportedParams, encName, unsupportedParam)
+	}
+
+	emit("func %s(f *testing.F) {\n", wrapperName)
+	if support == nativeSupport {
+		emit("\tf.Fuzz(func(t *testing.T, %s %s) {\n", name, typ)
+	} else {
+		emit("\tf.Fuzz(func(t *testing.T, data []byte) {\n")
+		emit("\t\tvar %s %s\n", name, typ)
+		emit("\t\tfz := %s\n", options.newFuzzerCall(dictVarName))
+		emit("\t\tfz.Fill(&%s)\n", name)
+	}
+	emitNilChecks(emit, inputParams, localPkg)
+	if _, ok := input.Type().(*types.Pointer); ok || support == fillRequired {
+		emit("\n")
+	}
+
+	// Encode.
+	switch {
+	case inv.kind == dstInverse:
+		emit("\tencoded := make([]byte, %s(len(%s)))\n", qualified(inv.encodedLen), name)
+		emit("\tencoded = encoded[:%s(encoded, %s)]\n", qualified(inv.encode), name)
+	case encErr:
+		emit("\tencoded, err := %s(%s)\n", qualified(inv.encode), name)
+		emit("\tif err != nil {\n")
+		emit("\t\treturn\n")
+		emit("\t}\n")
+	default:
+		emit("\tencoded := %s(%s)\n", qualified(inv.encode), name)
+	}
+	emit("\n")
+
+	// Decode.
+	decResults, decErr := resultsWithoutError(decSig)
+	decoded := "decoded"
+	switch inv.kind {
+	case valueInverse:
+		if decErr {
+			emit("\tdecoded, err := %s(encoded)\n", qualified(inv.decode))
+		} else {
+			emit("\tdecoded := %s(encoded)\n", qualified(inv.decode))
+		}
+	case outParamInverse:
+		assign := ":="
+		if encErr {
+			assign = "="
+		}
+		emit("\tvar decoded %s\n", typ)
+		emit("\terr %s %s(encoded, &decoded)\n", assign, qualified(inv.decode))
+	case dstInverse:
+		// Some decoders, such as ascii85.Decode, need room in dst beyond the decoded data,
+		// so we allocate generously.
+		emit("\tdecoded := make([]byte, 4*len(encoded))\n")
+		lhs := "ndst, err"
+		if len(decResults) == 2 {
+			lhs = "ndst, _, err"
+		}
+		flush := ""
+		if decSig.Params().Len() == 3 {
+			flush = ", true"
+		}
+		emit("\t%s := %s(decoded, encoded%s)\n", lhs, qualified(inv.decode), flush)
+		decoded = "decoded[:ndst]"
+	}
+	if decErr {
+		emit("\tif err != nil {\n")
+		emit("\t\tpanic(fmt.Sprintf(\"%s failed after successful %s. original: %%#v encoded: %%#v error: %%v\", %s, encoded, err))\n",
+			decName, encName, name)
+		emit("\t}\n")
+	}
+
+	// Compare. fuzzer.DiffRoundtrip considers nil and empty values equal, as does bytes.Equal.
+	if isByteSlice(input.Type()) {
+		emit("\tif !bytes.Equal(%s, %s) {\n", name, decoded)
+		emit("\t\tpanic(fmt.Sprintf(\"%s/%s roundtrip mismatch. original: %%#v encoded: %%#v decoded: %%#v\", %s, encoded, %s))\n",
+			encName, decName, name, decoded)
+	} else {
+		emit("\tif diff := fuzzer.DiffRoundtrip(%s, %s); diff != \"\" {\n", name, decoded)
+		emit("\t\tpanic(fmt.Sprintf(\"%s/%s roundtrip mismatch (-original +decoded):\\n%%s\\noriginal: %%#v encoded: %%#v decoded: %%#v\", diff, %s, encoded, %s))\n",
+			encName, decName, name, decoded)
+	}
+	emit("\t}\n")
+	emit("\t})\n")
+	emit("}\n\n")
+	return nil
+}
+
+// emitInverseWrappers emits a roundtrip wrapper for each package-level function in functions
+// that has an inverse function in the same package.
+func emitInverseWrappers(emit emitFunc, functions []mod.Func, options wrapperOptions) bool {
+	var success bool
+	for _, function := range functions {
+		inv, ok := findInverse(function.TypesFunc)
+		if !ok {
+			continue
+		}
+		if err := emitInverseWrapper(emit, inv, options); err == nil {
+			success = true
+		}
+	}
+	return success
+}
@@ -0,0 +1,233 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// keepDirective marks a wrapper in an existing output file that should be preserved as is
+// when merging in newly generated wrappers.
+const keepDirective = "//fzgen:keep"
+
+// mergeResult reports what happened to the wrappers in an existing file during a merge.
+type mergeResult struct {
+	stale    []string // kept wrappers that no longer have a corresponding generated wrapper
+	removed  []string // unmarked wrappers that no longer have a corresponding generated wrapper
+	replaced []string // unmarked wrappers that differ from the generated wrapper that replaced them
+}
+
+// mergeWrappers merges newly generated wrappers into the contents of an existing output file.
+// The result starts with the generated wrappers, with these adjustments:
+//   - a wrapper in the existing file marked with a //fzgen:keep comment replaces the
+//     generated wrapper of the same name, and is retained even if it is no longer generated.
+//   - other declarations in the existing file that are not fuzzing wrappers (such as helper funcs)
+//     are retained, along with the existing imports.
+//   - existing wrappers that are not marked and are no longer generated are removed.
+//   - existing wrappers that are not marked but differ from the generated wrapper of the same name,
+//     such as due to an edit without a //fzgen:keep comment, are replaced and reported.
+//
+// The imports in the result might need to be cleaned up by imports.Process.
+func mergeWrappers(existing, generated []byte) ([]byte, mergeResult, error) {
+	var result mergeResult
+	fset := token.NewFileSet()
+	oldFile, err := parser.ParseFile(fset, "existing.go", existing, parser.ParseComments)
+	if err != nil {
+		return nil, result, fmt.Errorf("parsing existing file: %v", err)
+	}
+	newFile, err := parser.ParseFile(fset, "generated.go", generated, parser.ParseComments)
+	if err != nil {
+		return nil, result, fmt.Errorf("parsing generated file: %v", err)
+	}
+
+	// Find the wrappers and other declarations in the existing file.
+	oldWrappers := make(map[string]ast.Decl)
+	var oldOther []ast.Decl
+	for _, decl := range oldFile.Decls {
+		if name, ok := wrapperName(decl); ok {
+			oldWrappers[name] = decl
+			continue
+		}
+		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+			continue
+		}
+		oldOther = append(oldOther, decl)
+	}
+
+	// Replace any generated wrappers that have a kept wrapper of the same name,
+	// working backwards so that the earlier offsets remain valid.
+	out := append([]byte(nil), generated...)
+	newNames := make(map[string]bool)
+	newDecls := make(map[string]bool)
+	for i := len(newFile.Decls) - 1; i >= 0; i-- {
+		decl := newFile.Decls[i]
+		name, ok := wrapperName(decl)
+		if !ok {
+			for _, n := range declNames(decl) {
+				newDecls[n] = true
+			}
+			continue
+		}
+		newNames[name] = true
+		oldDecl, ok := oldWrappers[name]
+		if !ok {
+			continue
+		}
+		if !hasKeepDirective(oldDecl) {
+			if !bytes.Equal(formatDecl(fset, oldFile, oldDecl), formatDecl(fset, newFile, decl)) {
+				result.replaced = append(result.replaced, name)
+			}
+			continue
+		}
+		start, end := declRange(fset, decl)
+		oldStart, oldEnd := declRange(fset, oldDecl)
+		out = append(out[:start:start], append(append([]byte(nil), existing[oldStart:oldEnd]...), out[end:]...)...)
+	}
+
+	// Append any kept wrappers that are no longer generated, as well as any other declarations
+	// from the existing file, in their original order.
+	var extra bytes.Buffer
+	for _, decl := range oldFile.Decls {
+		name, isWrapper := wrapperName(decl)
+		switch {
+		case isWrapper && newNames[name]:
+			continue
+		case isWrapper && !hasKeepDirective(decl):
+			result.removed = append(result.removed, name)
+			continue
+		case isWrapper:
+			result.stale = append(result.stale, name)
+		case !isWrapper:
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+				continue
+			}
+			if names := declNames(decl); len(names) > 0 && allTrue(newDecls, names) {
+				// Replaced by a generated declaration, such as a dictionary.
+				continue
+			}
+		}
+		start, end := declRange(fset, decl)
+		extra.WriteString("\n")
+		extra.Write(existing[start:end])
+		extra.WriteString("\n")
+	}
+	out = append(bytes.TrimRight(out, "\n"), '\n')
+	out = append(out, extra.Bytes()...)
+
+	// Retain the existing imports, which might be needed by kept wrappers or other declarations.
+	mergedFset := token.NewFileSet()
+	merged, err := parser.ParseFile(mergedFset, "merged.go", out, parser.ParseComments)
+	if err != nil {
+		return nil, result, fmt.Errorf("parsing merged file: %v", err)
+	}
+	for _, imp := range oldFile.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		var name string
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		astutil.AddNamedImport(mergedFset, merged, name, path)
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, mergedFset, merged); err != nil {
+		return nil, result, fmt.Errorf("formatting merged file: %v", err)
+	}
+
+	sort.Strings(result.replaced)
+	return buf.Bytes(), result, nil
+}
+
+// wrapperName returns the name of a decl if it is a top-level func that looks like a fuzzing wrapper.
+func wrapperName(decl ast.Decl) (string, bool) {
+	fd, ok := decl.(*ast.FuncDecl)
+	if !ok || fd.Recv != nil || !strings.HasPrefix(fd.Name.Name, "Fuzz") {
+		return "", false
+	}
+	return fd.Name.Name, true
+}
+
+// declNames returns the names declared by a top-level func, var, const, or type decl.
+func declNames(decl ast.Decl) []string {
+	var names []string
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil {
+			names = append(names, d.Name.Name)
+		}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch sp := spec.(type) {
+			case *ast.ValueSpec:
+				for _, n := range sp.Names {
+					names = append(names, n.Name)
+				}
+			case *ast.TypeSpec:
+				names = append(names, sp.Name.Name)
+			}
+		}
+	}
+	return names
+}
+
+// allTrue reports whether m[k] is true for all keys.
+func allTrue(m map[string]bool, keys []string) bool {
+	for _, k := range keys {
+		if !m[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasKeepDirective reports whether a func has a //fzgen:keep comment in its doc comment.
+func hasKeepDirective(decl ast.Decl) bool {
+	fd, ok := decl.(*ast.FuncDecl)
+	if !ok || fd.Doc == nil {
+		return false
+	}
+	for _, c := range fd.Doc.List {
+		if strings.HasPrefix(c.Text, keepDirective) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatDecl returns a decl from file formatted by gofmt, including its comments.
+// The generated wrappers are not yet formatted, so we compare formatted decls
+// rather than the original text when checking if an existing wrapper was edited.
+func formatDecl(fset *token.FileSet, file *ast.File, decl ast.Decl) []byte {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, &printer.CommentedNode{Node: decl, Comments: file.Comments}); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// declRange returns the byte offsets for a decl, including its doc comment.
+func declRange(fset *token.FileSet, decl ast.Decl) (start, end int) {
+	pos := decl.Pos()
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Doc != nil {
+			pos = d.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if d.Doc != nil {
+			pos = d.Doc.Pos()
+		}
+	}
+	return fset.Position(pos).Offset, fset.Position(decl.End()).Offset
+}
@@ -0,0 +1,220 @@
+package gen
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMergeWrappers(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     string
+		generated    string
+		want         string
+		wantStale    []string
+		wantRemoved  []string
+		wantReplaced []string
+	}{
+		{
+			name: "kept wrapper replaces generated wrapper",
+			existing: `package examplefuzz
+
+import (
+	"testing"
+	"strings"
+)
+
+//fzgen:keep
+func Fuzz_A(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		if strings.HasPrefix(s, "x") {
+			return
+		}
+		A(s)
+	})
+}
+
+func Fuzz_B(f *testing.F) {
+	f.Fuzz(func(t *testing.T, i int) {
+		B(i)
+	})
+}
+`,
+			generated: `package examplefuzz
+
+import "testing"
+
+func Fuzz_A(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		A(s)
+	})
+}
+
+func Fuzz_B(f *testing.F) {
+	f.Fuzz(func(t *testing.T, i int, j int) {
+		B(i, j)
+	})
+}
+
+func Fuzz_C(f *testing.F) {
+	f.Fuzz(func(t *testing.T, i int) {
+		C(i)
+	})
+}
+`,
+			want: `package examplefuzz
+
+import (
+	"strings"
+	"testing"
+)
+
+//fzgen:keep
+func Fuzz_A(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		if strings.HasPrefix(s, "x") {
+			return
+		}
+		A(s)
+	})
+}
+
+func Fuzz_B(f *testing.F) {
+	f.Fuzz(func(t *testing.T, i int, j int) {
+		B(i, j)
+	})
+}
+
+func Fuzz_C(f *testing.F) {
+	f.Fuzz(func(t *testing.T, i int) {
+		C(i)
+	})
+}
+`,
+			wantReplaced: []string{"Fuzz_B"},
+		},
+		{
+			name: "stale wrappers are removed unless kept",
+			existing: `package examplefuzz
+
+import "testing"
+
+func Fuzz_Old(f *testing.F) {
+	f.Fuzz(func(t *testing.T, i int) {
+		Old(i)
+	})
+}
+
+// Fuzz_OldKept is hand tuned.
+//
+//fzgen:keep
+func Fuzz_OldKept(f *testing.F) {
+	f.Fuzz(func(t *testing.T, i int) {
+		OldKept(i)
+	})
+}
+
+func helper() int { return 42 }
+`,
+			generated: `package examplefuzz
+
+import "testing"
+
+func Fuzz_A(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		A(s)
+	})
+}
+`,
+			want: `package examplefuzz
+
+import "testing"
+
+func Fuzz_A(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		A(s)
+	})
+}
+
+// Fuzz_OldKept is hand tuned.
+//
+//fzgen:keep
+func Fuzz_OldKept(f *testing.F) {
+	f.Fuzz(func(t *testing.T, i int) {
+		OldKept(i)
+	})
+}
+
+func helper() int { return 42 }
+`,
+			wantStale:   []string{"Fuzz_OldKept"},
+			wantRemoved: []string{"Fuzz_Old"},
+		},
+		{
+			name: "generated dictionary replaces existing dictionary",
+			existing: `package examplefuzz
+
+import "testing"
+
+func Fuzz_A(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		A(data)
+	})
+}
+
+var fuzzDictionary = fuzzer.Dictionary{
+	Strings: []string{"old"},
+}
+`,
+			generated: `package examplefuzz
+
+import "testing"
+
+func Fuzz_A(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		A(data)
+	})
+}
+
+var fuzzDictionary = fuzzer.Dictionary{
+	Strings: []string{"new"},
+}
+`,
+			want: `package examplefuzz
+
+import "testing"
+
+func Fuzz_A(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		A(data)
+	})
+}
+
+var fuzzDictionary = fuzzer.Dictionary{
+	Strings: []string{"new"},
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, res, err := mergeWrappers([]byte(tt.existing), []byte(tt.generated))
+			if err != nil {
+				t.Fatalf("mergeWrappers() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, string(got)); diff != "" {
+				t.Errorf("mergeWrappers() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantStale, res.stale); diff != "" {
+				t.Errorf("mergeWrappers() stale mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantRemoved, res.removed); diff != "" {
+				t.Errorf("mergeWrappers() removed mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantReplaced, res.replaced); diff != "" {
+				t.Errorf("mergeWrappers() replaced mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
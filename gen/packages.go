@@ -1,7 +1,9 @@
 package gen
 
 import (
+	"context"
 	"fmt"
+	"go/build/constraint"
 	"go/types"
 	"os"
 	"os/exec"
@@ -32,9 +34,17 @@ type pkg struct {
 	constructors []mod.Func
 }
 
-// findFuncsGrouped searches for requested functions matching a package pattern and func pattern,
-// returning them grouped by package.
-func findFuncsGrouped(pkgPattern, funcPattern, constructorPattern string, flags findFuncFlag) ([]*pkg, error) {
+// loadOptions controls how the go command is used to load target packages.
+// The zero value loads packages from the current directory without any build flags.
+type loadOptions struct {
+	ctx        context.Context // nil means context.Background()
+	dir        string          // directory in which to run the go command. empty means the current directory.
+	buildFlags []string        // passed to the go command, such as []string{"-tags=foo,bar"}. may be nil.
+}
+
+// findFuncsGrouped searches for requested functions matching a list of package patterns and a func pattern,
+// returning them grouped by package. All packages are loaded together in one call to packages.Load.
+func findFuncsGrouped(pkgPatterns []string, funcPattern, constructorPattern string, lo loadOptions, flags findFuncFlag) ([]*pkg, error) {
 	report := func(err error) ([]*pkg, error) {
 		return nil, fmt.Errorf("finding funcs: %v", err)
 	}
@@ -49,7 +59,7 @@ func findFuncsGrouped(pkgPattern, funcPattern, constructorPattern string, flags
 	}
 	comboPattern := fmt.Sprintf("(%s)|(%s)", funcPattern, constructorPattern)
 
-	allFunctions, err := findFuncs(pkgPattern, comboPattern, nil, flags)
+	allFunctions, err := findFuncs(pkgPatterns, comboPattern, nil, lo, flags)
 	if err != nil {
 		return report(err)
 	}
@@ -93,20 +103,23 @@ func findFuncsGrouped(pkgPattern, funcPattern, constructorPattern string, flags
 	return pkgs, nil
 }
 
-// findFuncs searches for requested functions matching a package pattern and func pattern.
+// findFuncs searches for requested functions matching a list of package patterns and func pattern.
 // TODO: this is a temporary fork from fzgo/fuzz.FindFunc.
 // TODO: maybe change flags to a predicate function?
-func findFuncs(pkgPattern, funcPattern string, env []string, flags findFuncFlag) ([]mod.Func, error) {
+func findFuncs(pkgPatterns []string, funcPattern string, env []string, lo loadOptions, flags findFuncFlag) ([]mod.Func, error) {
+	pkgPattern := strings.Join(pkgPatterns, " ") // for error messages
 	report := func(err error) error {
 		return fmt.Errorf("error while loading packages for pattern %v: %v", pkgPattern, err)
 	}
 	var result []mod.Func
 
-	// load packages based on our package pattern
-	// TODO: set build tags? Previously: BuildFlags: []string{buildTagsArg}, retain? probably not needed.
-	// build tags example: https://groups.google.com/d/msg/golang-tools/Adwr7jEyDmw/wQZ5qi8ZGAAJ
+	// load packages based on our package pattern.
+	// GOOS and GOARCH are respected via the environment, and build tags via buildFlags.
 	cfg := &packages.Config{
-		Mode: packages.LoadSyntax,
+		Mode:       packages.LoadSyntax,
+		Context:    lo.ctx,
+		Dir:        lo.dir,
+		BuildFlags: lo.buildFlags,
 		// TODO: packages.LoadSyntax is deprecated, so consider something similar to:
 		//    Mode: packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
 		// However, that specific change is not correct.
@@ -119,7 +132,7 @@ func findFuncs(pkgPattern, funcPattern string, env []string, flags findFuncFlag)
 	if len(env) > 0 {
 		cfg.Env = env
 	}
-	pkgs, err := packages.Load(cfg, pkgPattern)
+	pkgs, err := packages.Load(cfg, pkgPatterns...)
 	if err != nil {
 		return nil, report(err)
 	}
@@ -167,7 +180,7 @@ func findFuncs(pkgPattern, funcPattern string, env []string, flags findFuncFlag)
 							pkgPattern, funcPattern, pkg.PkgPath, id.Name, result[0].PkgPath, result[0].FuncName)
 					}
 					if pkgDir == "" {
-						pkgDir, err = goListDir(pkg.PkgPath, env)
+						pkgDir, err = goListDir(pkg.PkgPath, env, lo)
 						if err != nil {
 							return nil, report(err)
 						}
@@ -190,15 +203,19 @@ func findFuncs(pkgPattern, funcPattern string, env []string, flags findFuncFlag)
 }
 
 // goListDir returns the dir for a package import path.
-func goListDir(pkgPath string, env []string) (string, error) {
+func goListDir(pkgPath string, env []string, lo loadOptions) (string, error) {
 	if len(env) == 0 {
 		env = os.Environ()
 	}
+	ctx := lo.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
-	// TODO: use build tags, or not?
-	// cmd := exec.Command("go", "list", "-f", "{{.Dir}}", buildTagsArg, pkgPath)
-	cmd := exec.Command("go", "list", "-f", "{{.Dir}}", pkgPath)
+	args := append(append([]string{"list", "-f", "{{.Dir}}"}, lo.buildFlags...), pkgPath)
+	cmd := exec.CommandContext(ctx, "go", args...)
 	cmd.Env = env
+	cmd.Dir = lo.dir
 	cmd.Stderr = os.Stderr
 
 	out, err := cmd.Output()
@@ -245,6 +262,24 @@ func goList(dir string, args ...string) ([]string, error) {
 	return result, nil
 }
 
+// goEnv returns the values of the named variables as reported by 'go env' in dir.
+func goEnv(dir string, names ...string) ([]string, error) {
+	cmd := exec.Command("go", append([]string{"env"}, names...)...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("'go env' failed: %v", err)
+	}
+	values := strings.Split(strings.TrimRight(string(out), "\r\n"), "\n")
+	if len(values) != len(names) {
+		return nil, fmt.Errorf("'go env' returned %d values for %d variables", len(values), len(names))
+	}
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values, nil
+}
+
 // isInModule reports if dir appears to be within a module with a 'go.mod'.
 func isInModule(dir string) (bool, error) {
 	cmd := exec.Command("go", "env", "GOMOD")
@@ -312,3 +347,48 @@ func isInterfaceRecv(f *types.Func) bool {
 	_, ok = recv.Type().Underlying().(*types.Interface)
 	return ok
 }
+
+// tagsBuildFlags returns the flags to pass to the go command for a list of build tags,
+// or nil if there are no build tags.
+func tagsBuildFlags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	return []string{"-tags=" + strings.Join(tags, ",")}
+}
+
+// splitTags splits the value of a -tags flag, which is a comma-separated list
+// (or for compatibility with older go versions, a space-separated list).
+func splitTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// buildConstraint returns a //go:build line that matches the build tags along with
+// GOOS or GOARCH if the go command is configured for something other than the host,
+// or the empty string if there are none.
+// This allows the generated file to only be built in the same configuration
+// that was used to load the target packages.
+func buildConstraint(dir string, tags []string) (string, error) {
+	// We ask the go command rather than checking our environment, which covers
+	// 'go env -w GOOS=...' as well as an fzgen binary built for a different platform.
+	env, err := goEnv(dir, "GOOS", "GOARCH", "GOHOSTOS", "GOHOSTARCH")
+	if err != nil {
+		return "", err
+	}
+	var terms []string
+	if env[0] != env[2] {
+		terms = append(terms, env[0])
+	}
+	if env[1] != env[3] {
+		terms = append(terms, env[1])
+	}
+	terms = append(terms, tags...)
+	if len(terms) == 0 {
+		return "", nil
+	}
+	line := "//go:build " + strings.Join(terms, " && ")
+	if _, err := constraint.Parse(line); err != nil {
+		return "", fmt.Errorf("invalid build tags %q: %v", tags, err)
+	}
+	return line, nil
+}
@@ -0,0 +1,70 @@
+package gen
+
+import (
+	"go/types"
+
+	"github.com/thepudds/fzgen/gen/internal/mod"
+)
+
+// Status describes what Generate did with a candidate function.
+type Status string
+
+const (
+	StatusNative  Status = "native"  // wrapped, with all parameters natively supported by cmd/go
+	StatusFill    Status = "fill"    // wrapped, with parameters filled via fz.Fill
+	StatusChain   Status = "chain"   // included as a step in a chain
+	StatusDiff    Status = "diff"    // compared with a reference package in a differential wrapper or chain
+	StatusSkipped Status = "skipped" // not wrapped. see Reason.
+)
+
+// Record reports the outcome for one candidate function or method, such as
+// whether a wrapper was generated and if not, why not.
+type Record struct {
+	Package     string   `json:"package"`               // import path of the target package
+	Receiver    string   `json:"receiver,omitempty"`    // receiver type for a method, such as "*Buffer"
+	Func        string   `json:"func"`                  // function or method name
+	TypeArgs    []string `json:"typeArgs,omitempty"`    // type arguments for an instantiated generic
+	Wrapper     string   `json:"wrapper,omitempty"`     // generated wrapper name, or the chain wrapper for a step
+	Status      Status   `json:"status"`                // outcome
+	Constructor string   `json:"constructor,omitempty"` // constructor used to create the receiver, if any
+	Unsupported string   `json:"unsupported,omitempty"` // offending parameter type when skipped due to unsupported parameters
+	Reason      string   `json:"reason,omitempty"`      // why the function was skipped
+}
+
+// newRecord returns a Record for function with the given status, without any outcome details.
+func newRecord(function mod.Func, status Status) Record {
+	f := function.TypesFunc
+	qualifier := types.RelativeTo(f.Pkg())
+	r := Record{Package: function.PkgPath, Func: f.Name(), Status: status}
+	if recv := f.Type().(*types.Signature).Recv(); recv != nil {
+		r.Receiver = types.TypeString(recv.Type(), qualifier)
+	}
+	for _, t := range function.TypeArgs {
+		r.TypeArgs = append(r.TypeArgs, types.TypeString(t, qualifier))
+	}
+	return r
+}
+
+// record passes r to the report callback, if any.
+func (o wrapperOptions) record(r Record) {
+	if o.report != nil {
+		o.report(r)
+	}
+}
+
+// skip records that function was skipped for reason.
+func (o wrapperOptions) skip(function mod.Func, reason string) {
+	r := newRecord(function, StatusSkipped)
+	r.Reason = reason
+	o.record(r)
+}
+
+// skipUnsupported records that function was skipped because of the unsupported parameter type.
+// wrapper is the chain wrapper that would have included function as a step, or empty if none.
+func (o wrapperOptions) skipUnsupported(function mod.Func, wrapper, unsupported string) {
+	r := newRecord(function, StatusSkipped)
+	r.Wrapper = wrapper
+	r.Unsupported = unsupported
+	r.Reason = "parameters include func, chan, or unsupported interface"
+	o.record(r)
+}
@@ -0,0 +1,243 @@
+package gen
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// roundtripKind describes how a roundtrip pattern encodes and decodes a chain's target.
+type roundtripKind int
+
+const (
+	// marshalRoundtrip is a pair of methods like MarshalText() ([]byte, error) and UnmarshalText([]byte) error.
+	marshalRoundtrip roundtripKind = iota
+	// appendRoundtrip is a pair of methods like AppendText([]byte) ([]byte, error) and UnmarshalText([]byte) error.
+	appendRoundtrip
+	// parseRoundtrip is a method like String() string or AppendX([]byte) []byte, along with
+	// a package-level function like Parse(string) (T, error) or ParseX([]byte) (*T, error).
+	parseRoundtrip
+)
+
+// roundtrip is a pattern of an encoding method on a chain's target and a corresponding decoding method
+// or package-level function, which we use to check that the target survives a roundtrip.
+type roundtrip struct {
+	kind   roundtripKind
+	encode string // method on the target, such as "MarshalText"
+	decode string // method for marshalRoundtrip and appendRoundtrip, or package-level function for parseRoundtrip
+}
+
+// roundtrips lists the patterns we check, in the order the checks are emitted.
+// The position in this list determines the names of the temporary variables in the emitted check
+// (e.g., result2 and tmp2 for MarshalBinary), so new patterns should be added at the end.
+// AppendX and ParseX pairs are found by appendParseRoundtrips and are checked after these.
+var roundtrips = []roundtrip{
+	{marshalRoundtrip, "MarshalText", "UnmarshalText"},     // encoding.TextMarshaler and encoding.TextUnmarshaler
+	{marshalRoundtrip, "MarshalBinary", "UnmarshalBinary"}, // encoding.BinaryMarshaler and encoding.BinaryUnmarshaler
+	{marshalRoundtrip, "MarshalJSON", "UnmarshalJSON"},     // json.Marshaler and json.Unmarshaler
+	{marshalRoundtrip, "GobEncode", "GobDecode"},           // gob.GobEncoder and gob.GobDecoder
+	{appendRoundtrip, "AppendText", "UnmarshalText"},       // encoding.TextAppender and encoding.TextUnmarshaler
+	{appendRoundtrip, "AppendBinary", "UnmarshalBinary"},   // encoding.BinaryAppender and encoding.BinaryUnmarshaler
+	{parseRoundtrip, "String", "Parse"},                    // fmt.Stringer and a package-level Parse
+}
+
+var (
+	byteSliceType = types.NewSlice(types.Typ[types.Byte])
+	stringType    = types.Typ[types.String]
+	errorType     = types.Universe.Lookup("error").Type()
+)
+
+// emitRoundtrips emits roundtrip validation checks for each pattern in roundtrips and
+// each AppendX and ParseX pair where the chain's target implements both halves.
+func emitRoundtrips(emit emitFunc, target chainTarget, options wrapperOptions) {
+	named := target.named
+	all := append([]roundtrip(nil), roundtrips...)
+	all = append(all, appendParseRoundtrips(named)...)
+
+	first := true
+	for i, rt := range all {
+		decodeArg, ok := rt.implementedBy(named)
+		if !ok {
+			continue
+		}
+		if first {
+			emit("\n// Validate with some roundtrip checks. These can be edited or deleted if not appropriate for your target.")
+			first = false
+		}
+		switch rt.kind {
+		case marshalRoundtrip, appendRoundtrip:
+			emitMethodRoundtrip(emit, rt, i+1, target, options)
+		case parseRoundtrip:
+			emitParseRoundtrip(emit, rt, i+1, decodeArg, target, options)
+		}
+	}
+}
+
+// implementedBy reports whether named implements both halves of the roundtrip.
+// For a parseRoundtrip, it also returns the parameter type of the decoding function,
+// which is either a string or a []byte.
+func (rt roundtrip) implementedBy(named *types.Named) (types.Type, bool) {
+	var encodeParams, encodeResults []types.Type
+	switch rt.kind {
+	case marshalRoundtrip:
+		encodeResults = []types.Type{byteSliceType, errorType}
+	case appendRoundtrip:
+		encodeParams = []types.Type{byteSliceType}
+		encodeResults = []types.Type{byteSliceType, errorType}
+	case parseRoundtrip:
+		if rt.encode == "String" {
+			encodeResults = []types.Type{stringType}
+		} else {
+			encodeParams = []types.Type{byteSliceType}
+			encodeResults = []types.Type{byteSliceType}
+		}
+	}
+	sig := method(named, rt.encode)
+	if sig == nil || !matches(sig, encodeParams, encodeResults) {
+		return nil, false
+	}
+
+	if rt.kind != parseRoundtrip {
+		sig = method(named, rt.decode)
+		return nil, sig != nil && matches(sig, []types.Type{byteSliceType}, []types.Type{errorType})
+	}
+
+	// Look for a package-level function like Parse(string) (T, error).
+	// A generic target would need a generic Parse, which we do not handle.
+	obj, ok := named.Obj().Pkg().Scope().Lookup(rt.decode).(*types.Func)
+	if !ok || !obj.Exported() || named.TypeArgs().Len() > 0 {
+		return nil, false
+	}
+	sig = obj.Type().(*types.Signature)
+	if sig.TypeParams().Len() > 0 || sig.Variadic() || sig.Params().Len() != 1 || sig.Results().Len() != 2 {
+		return nil, false
+	}
+	param := sig.Params().At(0).Type()
+	if !types.Identical(param, stringType) && !types.Identical(param, byteSliceType) {
+		return nil, false
+	}
+	result := sig.Results().At(0).Type()
+	if ptr, ok := result.(*types.Pointer); ok {
+		result = ptr.Elem()
+	}
+	if !types.Identical(result, named) || !types.Identical(sig.Results().At(1).Type(), errorType) {
+		return nil, false
+	}
+	return param, true
+}
+
+// appendParseRoundtrips returns a roundtrip for each method like AppendX on named
+// that has a corresponding package-level function like ParseX, sorted by method name.
+// Whether the signatures match is checked later by implementedBy.
+func appendParseRoundtrips(named *types.Named) []roundtrip {
+	var result []roundtrip
+	mset := types.NewMethodSet(types.NewPointer(named))
+	for i := 0; i < mset.Len(); i++ {
+		name := mset.At(i).Obj().Name()
+		x := strings.TrimPrefix(name, "Append")
+		if x == name || !token.IsExported(x) {
+			continue
+		}
+		if named.Obj().Pkg().Scope().Lookup("Parse"+x) == nil {
+			continue
+		}
+		result = append(result, roundtrip{parseRoundtrip, name, "Parse" + x})
+	}
+	return result
+}
+
+// method returns the signature of the named method in the method set of *named,
+// which includes the methods with value receivers, or nil if there is no such method.
+func method(named *types.Named, name string) *types.Signature {
+	sel := types.NewMethodSet(types.NewPointer(named)).Lookup(named.Obj().Pkg(), name)
+	if sel == nil {
+		return nil
+	}
+	sig, _ := sel.Type().(*types.Signature)
+	return sig
+}
+
+// matches reports whether sig has exactly the given param and result types.
+func matches(sig *types.Signature, params, results []types.Type) bool {
+	if sig.Variadic() || sig.Params().Len() != len(params) || sig.Results().Len() != len(results) {
+		return false
+	}
+	for i, p := range params {
+		if !types.Identical(sig.Params().At(i).Type(), p) {
+			return false
+		}
+	}
+	for i, r := range results {
+		if !types.Identical(sig.Results().At(i).Type(), r) {
+			return false
+		}
+	}
+	return true
+}
+
+// emitMethodRoundtrip emits a check for a marshalRoundtrip or appendRoundtrip,
+// which decodes into a new value and compares it with the target via fuzzer.DiffRoundtrip.
+// Each check is in its own if statement so that an error from encoding only skips that check.
+func emitMethodRoundtrip(emit emitFunc, rt roundtrip, n int, target chainTarget, options wrapperOptions) {
+	defaultQualifier, _ := qualifiers(target.named.Obj().Pkg(), options.qualifyAll)
+	encodeArgs := ""
+	if rt.kind == appendRoundtrip {
+		encodeArgs = "nil"
+	}
+	// If the target is a pointer, we compare it with a pointer to our temporary variable.
+	tmp := "tmp%[3]d"
+	if target.pointer {
+		tmp = "&tmp%[3]d"
+	}
+	emit(`
+		// Check %[1]s and %[2]s.
+		// Some targets should never return an error from %[1]s for an object created by a constructor.
+		// If that is the case for your target, you can add an else branch that calls panic(err) or t.Fatal.
+		if result%[3]d, err := target.%[1]s(%[5]s); err == nil {
+			var tmp%[3]d %[4]s
+			err = tmp%[3]d.%[2]s(result%[3]d)
+			if err != nil {
+				panic(fmt.Sprintf("%[2]s failed after successful %[1]s. original: %%v %%#v marshalled: %%q error: %%v", target, target, result%[3]d, err))
+			}
+			if diff := fuzzer.DiffRoundtrip(target, `+tmp+`); diff != "" {
+				panic(fmt.Sprintf("%[1]s/%[2]s roundtrip equality failed (-original +unmarshalled):\n%%s\noriginal: %%v %%#v marshalled: %%q unmarshalled: %%v %%#v",
+					diff, target, target, result%[3]d, tmp%[3]d, tmp%[3]d))
+			}
+		}
+`, rt.encode, rt.decode, n, types.TypeString(target.named, defaultQualifier), encodeArgs)
+}
+
+// emitParseRoundtrip emits a check for a parseRoundtrip. The parsed value might be a pointer even if the target
+// is not, or vice versa, so rather than comparing the values, we check that it encodes to the same result.
+// As with emitMethodRoundtrip, an error from parsing only skips this check.
+// decodeArg is the parameter type of the decoding function.
+func emitParseRoundtrip(emit emitFunc, rt roundtrip, n int, decodeArg types.Type, target chainTarget, options wrapperOptions) {
+	decode := rt.decode
+	if options.qualifyAll {
+		decode = target.named.Obj().Pkg().Name() + "." + decode
+	}
+	encodeArgs, compare := "", "tmp%[3]d.%[1]s(%[5]s) != result%[3]d"
+	if rt.encode != "String" {
+		encodeArgs, compare = "nil", "!bytes.Equal(tmp%[3]d.%[1]s(%[5]s), result%[3]d)"
+	}
+	arg := fmt.Sprintf("result%d", n)
+	switch {
+	case rt.encode == "String" && types.Identical(decodeArg, byteSliceType):
+		arg = fmt.Sprintf("[]byte(%s)", arg)
+	case rt.encode != "String" && types.Identical(decodeArg, stringType):
+		arg = fmt.Sprintf("string(%s)", arg)
+	}
+	emit(`
+		// Check %[1]s and %[2]s.
+		// Some targets do not produce a form that %[2]s accepts for every value, such as an invalid or zero value.
+		// If that is not the case for your target, you can add an else branch that calls panic(err) or t.Fatal.
+		result%[3]d := target.%[1]s(%[5]s)
+		if tmp%[3]d, err := %[4]s(%[6]s); err == nil {
+			if `+compare+` {
+				panic(fmt.Sprintf("%[1]s/%[2]s roundtrip equality failed. original: %%v %%#v encoded: %%q parsed: %%v %%#v",
+					target, target, result%[3]d, tmp%[3]d, tmp%[3]d))
+			}
+		}
+`, rt.encode, rt.decode, n, decode, encodeArgs, arg)
+}
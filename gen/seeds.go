@@ -0,0 +1,418 @@
+package gen
+
+import (
+	"encoding/binary"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxSeeds caps how many seed corpus entries we emit for a single wrapper.
+const maxSeeds = 10
+
+// seedCalls holds the arguments for calls found in a package's test files,
+// keyed by function name for functions and constructors (e.g., "Parse"),
+// or by a leading dot and the method name for method calls (e.g., ".Add").
+// We do not type check the test files, so a method call key
+// might match methods on multiple types. The args are Go expressions.
+type seedCalls map[string][][]string
+
+// harvestSeeds parses the _test.go files in dir, and returns the arguments
+// used in calls to functions and methods where all of the arguments are constants,
+// such as 'Parse("1.2.3")' in a test or 'strconv.Quote("hello")' in an Example.
+// pkgPath and pkgName are the import path and name of the target package, which are used
+// to recognize qualified calls from an external test package.
+func harvestSeeds(dir string, pkgPath string, pkgName string) (seedCalls, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, err
+	}
+	result := make(seedCalls)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, file, src, 0)
+		if err != nil {
+			return nil, err
+		}
+		// Determine what name is used to refer to the target package, if imported.
+		importName := ""
+		if f.Name.Name != pkgName {
+			for _, imp := range f.Imports {
+				path, err := strconv.Unquote(imp.Path.Value)
+				if err != nil || path != pkgPath {
+					continue
+				}
+				importName = pkgName
+				if imp.Name != nil {
+					importName = imp.Name.Name
+				}
+			}
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 || call.Ellipsis != token.NoPos {
+				return true
+			}
+			var key string
+			switch fun := call.Fun.(type) {
+			case *ast.Ident:
+				if f.Name.Name != pkgName {
+					// An external test package, so not a call to the target package.
+					return true
+				}
+				key = fun.Name
+			case *ast.SelectorExpr:
+				if x, ok := fun.X.(*ast.Ident); ok && importName != "" && x.Name == importName {
+					key = fun.Sel.Name
+				} else {
+					key = "." + fun.Sel.Name
+				}
+			default:
+				return true
+			}
+			var args []string
+			for _, arg := range call.Args {
+				expr, ok := constantExpr(arg)
+				if !ok {
+					return true
+				}
+				args = append(args, expr)
+			}
+			for _, existing := range result[key] {
+				if strings.Join(existing, ",") == strings.Join(args, ",") {
+					return true
+				}
+			}
+			result[key] = append(result[key], args)
+			return true
+		})
+	}
+	return result, nil
+}
+
+// constantExpr reports whether an argument is a literal or simple constant expression
+// that we can use as a seed value, such as 42, -1, "hello", true, or []byte("hello").
+// It returns the expression as a string, with any []byte conversion removed.
+// A nil argument is returned as an empty string, which means the zero value.
+func constantExpr(arg ast.Expr) (string, bool) {
+	switch a := arg.(type) {
+	case *ast.BasicLit:
+		return a.Value, true
+	case *ast.Ident:
+		switch a.Name {
+		case "true", "false":
+			return a.Name, true
+		case "nil":
+			return "", true
+		}
+	case *ast.UnaryExpr:
+		if lit, ok := a.X.(*ast.BasicLit); ok && (a.Op == token.SUB || a.Op == token.ADD) {
+			return a.Op.String() + lit.Value, true
+		}
+	case *ast.ParenExpr:
+		return constantExpr(a.X)
+	case *ast.CallExpr:
+		// Allow []byte("hello").
+		if at, ok := a.Fun.(*ast.ArrayType); ok && at.Len == nil && len(a.Args) == 1 {
+			if elt, ok := at.Elt.(*ast.Ident); ok && (elt.Name == "byte" || elt.Name == "uint8") {
+				if lit, ok := a.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					return lit.Value, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// paramSource records where a wrapper parameter comes from,
+// which is either a parameter of the function under test or of an inserted constructor,
+// or the receiver of the method under test (in which case arg is -1).
+type paramSource struct {
+	f   *types.Func
+	arg int
+}
+
+// seedVectors returns candidate seed values for the parameters of a wrapper, with one
+// expression per parameter, or an empty string for a parameter that should use its zero value.
+// The values for the function under test come from calls to that function (or method),
+// and the values for an inserted constructor come from calls to the constructor.
+// If there are calls to both, the first constructor call is paired with each call to the function.
+func seedVectors(seeds seedCalls, sources []paramSource) [][]string {
+	if len(seeds) == 0 || len(sources) == 0 {
+		return nil
+	}
+	key := func(f *types.Func) string {
+		if receiver(f) != nil {
+			return "." + f.Name()
+		}
+		return f.Name()
+	}
+
+	// Determine which functions our params come from, in order.
+	var funcs []*types.Func
+	counts := make(map[*types.Func]int)
+	for _, s := range sources {
+		if s.arg < 0 {
+			continue
+		}
+		if counts[s.f] == 0 {
+			funcs = append(funcs, s.f)
+		}
+		counts[s.f]++
+	}
+
+	// Find the calls for each function that have the expected count of arguments.
+	calls := make(map[*types.Func][][]string)
+	for _, f := range funcs {
+		for _, args := range seeds[key(f)] {
+			if len(args) == counts[f] {
+				calls[f] = append(calls[f], args)
+			}
+		}
+	}
+
+	vector := func(chosen map[*types.Func][]string) []string {
+		v := make([]string, len(sources))
+		for i, s := range sources {
+			if args, ok := chosen[s.f]; ok && s.arg >= 0 {
+				v[i] = args[s.arg]
+			}
+		}
+		return v
+	}
+
+	var result [][]string
+	switch len(funcs) {
+	case 0:
+		return nil
+	case 1:
+		for _, args := range calls[funcs[0]] {
+			result = append(result, vector(map[*types.Func][]string{funcs[0]: args}))
+		}
+	default:
+		// A constructor followed by the function under test.
+		ctor, f := funcs[0], funcs[len(funcs)-1]
+		var ctorArgs []string
+		if len(calls[ctor]) > 0 {
+			ctorArgs = calls[ctor][0]
+		}
+		for _, args := range calls[f] {
+			chosen := map[*types.Func][]string{f: args}
+			if ctorArgs != nil {
+				chosen[ctor] = ctorArgs
+			}
+			result = append(result, vector(chosen))
+		}
+		if len(calls[f]) == 0 {
+			for _, args := range calls[ctor] {
+				result = append(result, vector(map[*types.Func][]string{ctor: args}))
+			}
+		}
+	}
+	return result
+}
+
+// emitSeeds emits f.Add calls for seed values harvested from the package's test files.
+// For wrappers natively supported by cmd/go, the seed values are passed as typed arguments to f.Add.
+// For wrappers that use fz.Fill, the seed values are encoded into the data []byte
+// in the format expected by fz.Fill.
+// Seeds that do not type check (e.g., due to a mismatched method of the same name
+// on a different type) are silently skipped.
+func emitSeeds(emit emitFunc, seeds seedCalls, sources []paramSource, paramReprs []paramRepr, support paramSupport, dict *dictionary, localPkg *types.Package) {
+	var emitted int
+	for _, v := range seedVectors(seeds, sources) {
+		if emitted >= maxSeeds {
+			return
+		}
+		var line string
+		var ok bool
+		switch support {
+		case nativeSupport:
+			line, ok = nativeSeed(v, paramReprs, localPkg)
+		case fillRequired:
+			line, ok = fillSeed(v, paramReprs, dict, localPkg)
+		}
+		if ok {
+			emit("\tf.Add(%s)\n", line)
+			emitted++
+		}
+	}
+}
+
+// nativeSeed returns the arguments for f.Add for a seed vector, such as 'string("hello"), int(42)'.
+func nativeSeed(v []string, paramReprs []paramRepr, localPkg *types.Package) (string, bool) {
+	var args []string
+	for i, p := range paramReprs {
+		if v[i] == "" {
+			args = append(args, zeroValue(p.v.Type(), p.typ))
+			continue
+		}
+		if _, ok := seedValue(v[i], p.v.Type(), localPkg); !ok {
+			return "", false
+		}
+		args = append(args, fmt.Sprintf("%s(%s)", p.typ, v[i]))
+	}
+	return strings.Join(args, ", "), true
+}
+
+// fillSeed returns the argument for f.Add for a seed vector for a wrapper that uses fz.Fill,
+// such as '[]byte("\x00\x05hello")', which encodes each value in order as fz.Fill would consume it.
+// We can only encode basic types and []byte, so we stop encoding at the first other type,
+// which is fine as long as no seed values follow it. Any remaining params are then
+// filled by fz.Fill from the exhausted data. If we have a dictionary, the encoding
+// follows what fz.Fill expects when using a fuzzer.Dictionary.
+func fillSeed(v []string, paramReprs []paramRepr, dict *dictionary, localPkg *types.Package) (string, bool) {
+	// fz.Fill reserves the first byte.
+	data := []byte{0}
+	for i, p := range paramReprs {
+		var val constant.Value
+		if v[i] != "" {
+			var ok bool
+			val, ok = seedValue(v[i], p.v.Type(), localPkg)
+			if !ok {
+				return "", false
+			}
+		}
+		b, ok := encodeFill(val, p.v.Type(), dict)
+		if !ok {
+			for _, rest := range v[i:] {
+				if rest != "" {
+					return "", false
+				}
+			}
+			break
+		}
+		data = append(data, b...)
+	}
+	return fmt.Sprintf("[]byte(%s)", strconv.Quote(string(data))), true
+}
+
+// seedValue type checks a seed expression as a value of type t, and returns its constant value.
+// For a []byte, the value is the constant string.
+func seedValue(expr string, t types.Type, localPkg *types.Package) (constant.Value, bool) {
+	var conv string
+	if isByteSlice(t) {
+		conv = fmt.Sprintf("string(%s)", expr)
+	} else {
+		conv = fmt.Sprintf("%s(%s)", types.TypeString(t, types.RelativeTo(localPkg)), expr)
+	}
+	tv, err := types.Eval(token.NewFileSet(), localPkg, token.NoPos, conv)
+	if err != nil || tv.Value == nil {
+		return nil, false
+	}
+	return tv.Value, true
+}
+
+// encodeFill encodes a constant value as the bytes that fz.Fill would consume to produce it.
+// A nil val encodes the zero value. It returns false for unsupported types.
+// With a dictionary, integers are preceded by a control byte, and strings
+// cannot have a length of 0xFE, which is the marker for a dictionary entry.
+func encodeFill(val constant.Value, t types.Type, dict *dictionary) ([]byte, bool) {
+	if isByteSlice(t) {
+		return encodeFillBytes(val, dict)
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return nil, false
+	}
+	info := b.Info()
+	switch {
+	case info&types.IsString != 0:
+		return encodeFillBytes(val, dict)
+	case info&types.IsBoolean != 0:
+		if val != nil && constant.BoolVal(val) {
+			return []byte{0x80}, true
+		}
+		return []byte{0}, true
+	case info&types.IsInteger != 0:
+		size := numericSize(b.Kind())
+		if size == 0 {
+			return nil, false
+		}
+		var bits uint64
+		if val != nil {
+			if i, exact := constant.Int64Val(val); exact {
+				bits = uint64(i)
+			} else if u, exact := constant.Uint64Val(val); exact {
+				bits = u
+			} else {
+				return nil, false
+			}
+		}
+		if dict.hasInts() {
+			// A control byte that does not select a dictionary entry.
+			return append([]byte{0}, littleEndian(bits, size)...), true
+		}
+		return littleEndian(bits, size), true
+	case info&types.IsFloat != 0:
+		var f float64
+		if val != nil {
+			f, _ = constant.Float64Val(val)
+		}
+		if b.Kind() == types.Float32 {
+			return littleEndian(uint64(math.Float32bits(float32(f))), 4), true
+		}
+		return littleEndian(math.Float64bits(f), 8), true
+	}
+	return nil, false
+}
+
+// encodeFillBytes encodes a string or []byte using a length byte, where 0xFF means zero length.
+func encodeFillBytes(val constant.Value, dict *dictionary) ([]byte, bool) {
+	var s string
+	if val != nil {
+		s = constant.StringVal(val)
+	}
+	switch {
+	case len(s) == 0:
+		return []byte{0xFF}, true
+	case len(s) >= 0xFF, len(s) == 0xFE && dict.hasStrings():
+		return nil, false
+	}
+	return append([]byte{byte(len(s))}, s...), true
+}
+
+// numericSize returns the count of bytes fz.Fill uses for an integer kind.
+// int and uint always use 8 bytes for consistency across platforms.
+func numericSize(k types.BasicKind) int {
+	switch k {
+	case types.Int, types.Int64, types.Uint, types.Uint64:
+		return 8
+	case types.Int32, types.Uint32:
+		return 4
+	case types.Int16, types.Uint16:
+		return 2
+	case types.Int8, types.Uint8:
+		return 1
+	}
+	return 0
+}
+
+func littleEndian(bits uint64, size int) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, bits)
+	return b[:size]
+}
+
+// isByteSlice reports whether t is a []byte.
+func isByteSlice(t types.Type) bool {
+	s, ok := t.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	b, ok := s.Elem().(*types.Basic)
+	return ok && b.Kind() == types.Uint8
+}
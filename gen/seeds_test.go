@@ -0,0 +1,96 @@
+package gen
+
+import (
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+// fillValues are the values filled by fz.Fill for TestFillSeed, in order.
+type fillValues struct {
+	S   string
+	B   []byte
+	I   int
+	I16 int16
+	U8  uint8
+	OK  bool
+	F   float64
+}
+
+// TestFillSeed checks that the seeds encoded by fillSeed are decoded by fz.Fill into the original values.
+func TestFillSeed(t *testing.T) {
+	newParam := func(name string, typ types.Type) paramRepr {
+		return paramRepr{paramName: name, typ: typ.String(), v: types.NewVar(token.NoPos, nil, name, typ)}
+	}
+	params := []paramRepr{
+		newParam("s", types.Typ[types.String]),
+		newParam("b", types.NewSlice(types.Typ[types.Uint8])),
+		newParam("i", types.Typ[types.Int]),
+		newParam("i16", types.Typ[types.Int16]),
+		newParam("u8", types.Typ[types.Uint8]),
+		newParam("ok", types.Typ[types.Bool]),
+		newParam("f", types.Typ[types.Float64]),
+	}
+	localPkg := types.NewPackage("example.com/seeds", "seeds")
+
+	tests := []struct {
+		name string
+		v    []string
+		dict *dictionary
+		want fillValues
+	}{
+		{
+			name: "literals",
+			v:    []string{`"hello"`, `"world"`, "42", "-7", "200", "true", "1.5"},
+			want: fillValues{S: "hello", B: []byte("world"), I: 42, I16: -7, U8: 200, OK: true, F: 1.5},
+		},
+		{
+			name: "zero values",
+			v:    []string{"", "", "", "", "", "", ""},
+			want: fillValues{B: []byte{}},
+		},
+		{
+			name: "dictionary without matches",
+			v:    []string{`"hello"`, `"world"`, "42", "-7", "200", "true", "1.5"},
+			dict: &dictionary{strings: []string{"v1", "v2"}, ints: []int64{1000, 2000}},
+			want: fillValues{S: "hello", B: []byte("world"), I: 42, I16: -7, U8: 200, OK: true, F: 1.5},
+		},
+		{
+			name: "dictionary with matches",
+			v:    []string{`"v2"`, `"v1"`, "2000", "1000", "", "false", "-2"},
+			dict: &dictionary{strings: []string{"v1", "v2"}, ints: []int64{1000, 2000}},
+			want: fillValues{S: "v2", B: []byte("v1"), I: 2000, I16: 1000, F: -2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed, ok := fillSeed(tt.v, params, tt.dict, localPkg)
+			if !ok {
+				t.Fatalf("fillSeed() failed for %v", tt.v)
+			}
+			if !strings.HasPrefix(seed, "[]byte(") || !strings.HasSuffix(seed, ")") {
+				t.Fatalf("fillSeed() returned unexpected seed: %s", seed)
+			}
+			data, err := strconv.Unquote(strings.TrimSuffix(strings.TrimPrefix(seed, "[]byte("), ")"))
+			if err != nil {
+				t.Fatalf("failed to unquote seed %s: %v", seed, err)
+			}
+
+			var opts []fuzzer.FuzzerOpt
+			if tt.dict != nil {
+				opts = append(opts, fuzzer.WithDictionary(fuzzer.Dictionary{Strings: tt.dict.strings, Ints: tt.dict.ints}))
+			}
+			fz := fuzzer.NewFuzzer([]byte(data), opts...)
+			var got fillValues
+			fz.Fill(&got.S, &got.B, &got.I, &got.I16, &got.U8, &got.OK, &got.F)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("fz.Fill() of seed %s mismatch (-want +got):\n%s", seed, diff)
+			}
+		})
+	}
+}
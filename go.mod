@@ -4,13 +4,13 @@ go 1.18
 
 require (
 	github.com/google/go-cmp v0.5.6
+	github.com/pkg/diff v0.0.0-20210226163009-20ebb0f2a09e
 	github.com/rogpeppe/go-internal v1.8.1
 	github.com/sanity-io/litter v1.5.1
 	golang.org/x/tools v0.17.0
 )
 
 require (
-	github.com/pkg/diff v0.0.0-20210226163009-20ebb0f2a09e // indirect
 	golang.org/x/mod v0.14.0 // indirect
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 	gopkg.in/errgo.v2 v2.1.0 // indirect
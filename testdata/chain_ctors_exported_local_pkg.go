@@ -0,0 +1,84 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_Parse_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		// Create our target using one of the constructors, chosen by fz.Choose.
+		var target *Buffer
+		var err error
+		switch fz.Choose(4) {
+		case 0:
+			var s string
+			fz.Fill(&s)
+			target, err = Parse(s)
+		case 1:
+			var b []byte
+			fz.Fill(&b)
+			target, err = FromBytes(b)
+		case 2:
+			target = NewBuffer()
+		case 3:
+			var limit int
+			fz.Fill(&limit)
+			target = NewBufferSize(limit)
+		}
+		if err != nil {
+			return
+		}
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Buffer_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Buffer_Write",
+				Func: func(p []byte) (int, error) {
+					return target.Write(p)
+				},
+			},
+			{
+				Name: "Fuzz_FromBytes",
+				Func: func(b []byte) (*Buffer, error) {
+					return FromBytes(b)
+				},
+			},
+			{
+				Name: "Fuzz_NewBuffer",
+				Func: func() *Buffer {
+					return NewBuffer()
+				},
+			},
+			{
+				Name: "Fuzz_NewBufferSize",
+				Func: func(limit int) *Buffer {
+					return NewBufferSize(limit)
+				},
+			},
+			{
+				Name: "Fuzz_NewBufferValue",
+				Func: func() Buffer {
+					return NewBufferValue()
+				},
+			},
+			{
+				Name: "Fuzz_Parse",
+				Func: func(s string) (*Buffer, error) {
+					return Parse(s)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
@@ -0,0 +1,69 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_NewBitmap_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		target := NewBitmap()
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Bitmap_And",
+				Func: func(other *Bitmap) {
+					target.And(other)
+				},
+			},
+			{
+				Name: "Fuzz_Bitmap_Has",
+				Func: func(i uint8) bool {
+					return target.Has(i)
+				},
+			},
+			{
+				Name: "Fuzz_Bitmap_Set",
+				Func: func(i uint8) {
+					target.Set(i)
+				},
+			},
+			{
+				Name: "Fuzz_Count",
+				Func: func() int {
+					return Count(*target)
+				},
+			},
+			{
+				Name: "Fuzz_HasAll",
+				Func: func(values []uint8) bool {
+					return HasAll(values, target)
+				},
+			},
+			{
+				Name: "Fuzz_Merge",
+				Func: func(src *Bitmap) {
+					Merge(target, src)
+				},
+			},
+			{
+				Name: "Fuzz_NewBitmap",
+				Func: func() *Bitmap {
+					return NewBitmap()
+				},
+			},
+			{
+				Name: "Fuzz_Union",
+				Func: func(bitmaps []*Bitmap) *Bitmap {
+					return Union(bitmaps...)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
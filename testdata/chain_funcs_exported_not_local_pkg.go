@@ -0,0 +1,70 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	chainfuncs "github.com/thepudds/fzgen/examples/inputs/test-chain-funcs"
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_NewBitmap_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		target := chainfuncs.NewBitmap()
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Bitmap_And",
+				Func: func(other *chainfuncs.Bitmap) {
+					target.And(other)
+				},
+			},
+			{
+				Name: "Fuzz_Bitmap_Has",
+				Func: func(i uint8) bool {
+					return target.Has(i)
+				},
+			},
+			{
+				Name: "Fuzz_Bitmap_Set",
+				Func: func(i uint8) {
+					target.Set(i)
+				},
+			},
+			{
+				Name: "Fuzz_Count",
+				Func: func() int {
+					return chainfuncs.Count(*target)
+				},
+			},
+			{
+				Name: "Fuzz_HasAll",
+				Func: func(values []uint8) bool {
+					return chainfuncs.HasAll(values, target)
+				},
+			},
+			{
+				Name: "Fuzz_Merge",
+				Func: func(src *chainfuncs.Bitmap) {
+					chainfuncs.Merge(target, src)
+				},
+			},
+			{
+				Name: "Fuzz_NewBitmap",
+				Func: func() *chainfuncs.Bitmap {
+					return chainfuncs.NewBitmap()
+				},
+			},
+			{
+				Name: "Fuzz_Union",
+				Func: func(bitmaps []*chainfuncs.Bitmap) *chainfuncs.Bitmap {
+					return chainfuncs.Union(bitmaps...)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
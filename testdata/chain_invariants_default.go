@@ -0,0 +1,109 @@
+package examplefuzz
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_NewRing_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var n uint8
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&n)
+
+		target := NewRing(n)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Ring_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Ring_Pop",
+				Func: func() (byte, bool) {
+					return target.Pop()
+				},
+			},
+			{
+				Name: "Fuzz_Ring_Push",
+				Func: func(b byte) {
+					target.Push(b)
+				},
+			},
+			{
+				Name: "Fuzz_Ring_Validate",
+				Func: func() error {
+					return target.Validate()
+				},
+			},
+			{
+				Name: "Fuzz_NewRing",
+				Func: func(n uint8) *Ring {
+					return NewRing(n)
+				},
+			},
+		}
+
+		// Check the target's invariants after each step. This can be edited or deleted if not appropriate for your target.
+		invariant := func() error {
+			if err := target.Validate(); err != nil {
+				return err
+			}
+			if n := target.Len(); n < 0 {
+				return fmt.Errorf("Len() = %d, want >= 0", n)
+			}
+			return nil
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps, fuzzer.ChainInvariant(invariant))
+	})
+}
+
+func Fuzz_NewSortedSet_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		target := NewSortedSet()
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_SortedSet_CheckInvariants",
+				Func: func() {
+					target.CheckInvariants()
+				},
+			},
+			{
+				Name: "Fuzz_SortedSet_Insert",
+				Func: func(x int) {
+					target.Insert(x)
+				},
+			},
+			{
+				Name: "Fuzz_SortedSet_Sorted",
+				Func: func() bool {
+					return target.Sorted()
+				},
+			},
+			{
+				Name: "Fuzz_NewSortedSet",
+				Func: func() *SortedSet {
+					return NewSortedSet()
+				},
+			},
+		}
+
+		// Check the target's invariants after each step. This can be edited or deleted if not appropriate for your target.
+		invariant := func() error {
+			target.CheckInvariants()
+			return nil
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps, fuzzer.ChainInvariant(invariant))
+	})
+}
@@ -0,0 +1,282 @@
+package examplefuzz
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	roundtrip "github.com/thepudds/fzgen/examples/inputs/test-chain-roundtrip"
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_NewFlags_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		target := roundtrip.NewFlags()
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Flags_AppendBinary",
+				Func: func(b []byte) ([]byte, error) {
+					return target.AppendBinary(b)
+				},
+			},
+			{
+				Name: "Fuzz_Flags_AppendCompact",
+				Func: func(b []byte) []byte {
+					return target.AppendCompact(b)
+				},
+			},
+			{
+				Name: "Fuzz_Flags_Clear",
+				Func: func(i uint8) {
+					target.Clear(i)
+				},
+			},
+			{
+				Name: "Fuzz_Flags_GobDecode",
+				Func: func(b []byte) error {
+					return target.GobDecode(b)
+				},
+			},
+			{
+				Name: "Fuzz_Flags_GobEncode",
+				Func: func() ([]byte, error) {
+					return target.GobEncode()
+				},
+			},
+			{
+				Name: "Fuzz_Flags_Has",
+				Func: func(i uint8) bool {
+					return target.Has(i)
+				},
+			},
+			{
+				Name: "Fuzz_Flags_MarshalBinary",
+				Func: func() ([]byte, error) {
+					return target.MarshalBinary()
+				},
+			},
+			{
+				Name: "Fuzz_Flags_Set",
+				Func: func(i uint8) {
+					target.Set(i)
+				},
+			},
+			{
+				Name: "Fuzz_Flags_UnmarshalBinary",
+				Func: func(b []byte) error {
+					return target.UnmarshalBinary(b)
+				},
+			},
+			{
+				Name: "Fuzz_NewFlags",
+				Func: func() *roundtrip.Flags {
+					return roundtrip.NewFlags()
+				},
+			},
+			{
+				Name: "Fuzz_ParseCompact",
+				Func: func(b []byte) (*roundtrip.Flags, error) {
+					return roundtrip.ParseCompact(b)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+
+		// Validate with some roundtrip checks. These can be edited or deleted if not appropriate for your target.
+		// Check MarshalBinary and UnmarshalBinary.
+		// Some targets should never return an error from MarshalBinary for an object created by a constructor.
+		// If that is the case for your target, you can add an else branch that calls panic(err) or t.Fatal.
+		if result2, err := target.MarshalBinary(); err == nil {
+			var tmp2 roundtrip.Flags
+			err = tmp2.UnmarshalBinary(result2)
+			if err != nil {
+				panic(fmt.Sprintf("UnmarshalBinary failed after successful MarshalBinary. original: %v %#v marshalled: %q error: %v", target, target, result2, err))
+			}
+			if diff := fuzzer.DiffRoundtrip(target, &tmp2); diff != "" {
+				panic(fmt.Sprintf("MarshalBinary/UnmarshalBinary roundtrip equality failed (-original +unmarshalled):\n%s\noriginal: %v %#v marshalled: %q unmarshalled: %v %#v",
+					diff, target, target, result2, tmp2, tmp2))
+			}
+		}
+
+		// Check GobEncode and GobDecode.
+		// Some targets should never return an error from GobEncode for an object created by a constructor.
+		// If that is the case for your target, you can add an else branch that calls panic(err) or t.Fatal.
+		if result4, err := target.GobEncode(); err == nil {
+			var tmp4 roundtrip.Flags
+			err = tmp4.GobDecode(result4)
+			if err != nil {
+				panic(fmt.Sprintf("GobDecode failed after successful GobEncode. original: %v %#v marshalled: %q error: %v", target, target, result4, err))
+			}
+			if diff := fuzzer.DiffRoundtrip(target, &tmp4); diff != "" {
+				panic(fmt.Sprintf("GobEncode/GobDecode roundtrip equality failed (-original +unmarshalled):\n%s\noriginal: %v %#v marshalled: %q unmarshalled: %v %#v",
+					diff, target, target, result4, tmp4, tmp4))
+			}
+		}
+
+		// Check AppendBinary and UnmarshalBinary.
+		// Some targets should never return an error from AppendBinary for an object created by a constructor.
+		// If that is the case for your target, you can add an else branch that calls panic(err) or t.Fatal.
+		if result6, err := target.AppendBinary(nil); err == nil {
+			var tmp6 roundtrip.Flags
+			err = tmp6.UnmarshalBinary(result6)
+			if err != nil {
+				panic(fmt.Sprintf("UnmarshalBinary failed after successful AppendBinary. original: %v %#v marshalled: %q error: %v", target, target, result6, err))
+			}
+			if diff := fuzzer.DiffRoundtrip(target, &tmp6); diff != "" {
+				panic(fmt.Sprintf("AppendBinary/UnmarshalBinary roundtrip equality failed (-original +unmarshalled):\n%s\noriginal: %v %#v marshalled: %q unmarshalled: %v %#v",
+					diff, target, target, result6, tmp6, tmp6))
+			}
+		}
+
+		// Check AppendCompact and ParseCompact.
+		// Some targets do not produce a form that ParseCompact accepts for every value, such as an invalid or zero value.
+		// If that is not the case for your target, you can add an else branch that calls panic(err) or t.Fatal.
+		result8 := target.AppendCompact(nil)
+		if tmp8, err := roundtrip.ParseCompact(result8); err == nil {
+			if !bytes.Equal(tmp8.AppendCompact(nil), result8) {
+				panic(fmt.Sprintf("AppendCompact/ParseCompact roundtrip equality failed. original: %v %#v encoded: %q parsed: %v %#v",
+					target, target, result8, tmp8, tmp8))
+			}
+		}
+	})
+}
+
+func Fuzz_NewVersion_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var major uint16
+		var minor uint16
+		var patch uint16
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&major, &minor, &patch)
+
+		target := roundtrip.NewVersion(major, minor, patch)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Version_Bump",
+				Func: func(part uint8) {
+					target.Bump(part)
+				},
+			},
+			{
+				Name: "Fuzz_Version_UnmarshalJSON",
+				Func: func(b []byte) error {
+					return target.UnmarshalJSON(b)
+				},
+			},
+			{
+				Name: "Fuzz_Version_UnmarshalText",
+				Func: func(b []byte) error {
+					return target.UnmarshalText(b)
+				},
+			},
+			{
+				Name: "Fuzz_Version_AppendText",
+				Func: func(b []byte) ([]byte, error) {
+					return target.AppendText(b)
+				},
+			},
+			{
+				Name: "Fuzz_Version_Less",
+				Func: func(other roundtrip.Version) bool {
+					return target.Less(other)
+				},
+			},
+			{
+				Name: "Fuzz_Version_MarshalJSON",
+				Func: func() ([]byte, error) {
+					return target.MarshalJSON()
+				},
+			},
+			{
+				Name: "Fuzz_Version_MarshalText",
+				Func: func() ([]byte, error) {
+					return target.MarshalText()
+				},
+			},
+			{
+				Name: "Fuzz_Version_String",
+				Func: func() string {
+					return target.String()
+				},
+			},
+			{
+				Name: "Fuzz_NewVersion",
+				Func: func(major uint16, minor uint16, patch uint16) roundtrip.Version {
+					return roundtrip.NewVersion(major, minor, patch)
+				},
+			},
+			{
+				Name: "Fuzz_Parse",
+				Func: func(s string) (roundtrip.Version, error) {
+					return roundtrip.Parse(s)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+
+		// Validate with some roundtrip checks. These can be edited or deleted if not appropriate for your target.
+		// Check MarshalText and UnmarshalText.
+		// Some targets should never return an error from MarshalText for an object created by a constructor.
+		// If that is the case for your target, you can add an else branch that calls panic(err) or t.Fatal.
+		if result1, err := target.MarshalText(); err == nil {
+			var tmp1 roundtrip.Version
+			err = tmp1.UnmarshalText(result1)
+			if err != nil {
+				panic(fmt.Sprintf("UnmarshalText failed after successful MarshalText. original: %v %#v marshalled: %q error: %v", target, target, result1, err))
+			}
+			if diff := fuzzer.DiffRoundtrip(target, tmp1); diff != "" {
+				panic(fmt.Sprintf("MarshalText/UnmarshalText roundtrip equality failed (-original +unmarshalled):\n%s\noriginal: %v %#v marshalled: %q unmarshalled: %v %#v",
+					diff, target, target, result1, tmp1, tmp1))
+			}
+		}
+
+		// Check MarshalJSON and UnmarshalJSON.
+		// Some targets should never return an error from MarshalJSON for an object created by a constructor.
+		// If that is the case for your target, you can add an else branch that calls panic(err) or t.Fatal.
+		if result3, err := target.MarshalJSON(); err == nil {
+			var tmp3 roundtrip.Version
+			err = tmp3.UnmarshalJSON(result3)
+			if err != nil {
+				panic(fmt.Sprintf("UnmarshalJSON failed after successful MarshalJSON. original: %v %#v marshalled: %q error: %v", target, target, result3, err))
+			}
+			if diff := fuzzer.DiffRoundtrip(target, tmp3); diff != "" {
+				panic(fmt.Sprintf("MarshalJSON/UnmarshalJSON roundtrip equality failed (-original +unmarshalled):\n%s\noriginal: %v %#v marshalled: %q unmarshalled: %v %#v",
+					diff, target, target, result3, tmp3, tmp3))
+			}
+		}
+
+		// Check AppendText and UnmarshalText.
+		// Some targets should never return an error from AppendText for an object created by a constructor.
+		// If that is the case for your target, you can add an else branch that calls panic(err) or t.Fatal.
+		if result5, err := target.AppendText(nil); err == nil {
+			var tmp5 roundtrip.Version
+			err = tmp5.UnmarshalText(result5)
+			if err != nil {
+				panic(fmt.Sprintf("UnmarshalText failed after successful AppendText. original: %v %#v marshalled: %q error: %v", target, target, result5, err))
+			}
+			if diff := fuzzer.DiffRoundtrip(target, tmp5); diff != "" {
+				panic(fmt.Sprintf("AppendText/UnmarshalText roundtrip equality failed (-original +unmarshalled):\n%s\noriginal: %v %#v marshalled: %q unmarshalled: %v %#v",
+					diff, target, target, result5, tmp5, tmp5))
+			}
+		}
+
+		// Check String and Parse.
+		// Some targets do not produce a form that Parse accepts for every value, such as an invalid or zero value.
+		// If that is not the case for your target, you can add an else branch that calls panic(err) or t.Fatal.
+		result7 := target.String()
+		if tmp7, err := roundtrip.Parse(result7); err == nil {
+			if tmp7.String() != result7 {
+				panic(fmt.Sprintf("String/Parse roundtrip equality failed. original: %v %#v encoded: %q parsed: %v %#v",
+					target, target, result7, tmp7, tmp7))
+			}
+		}
+	})
+}
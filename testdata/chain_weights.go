@@ -0,0 +1,49 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_NewBuffer_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		target := NewBuffer()
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Buffer_Close",
+				// Close is chosen less often than steps with the default Weight of 4, so that more steps run before it.
+				Weight: 1,
+				Func: func() error {
+					return target.Close()
+				},
+			},
+			{
+				Name: "Fuzz_Buffer_Reset",
+				// Reset is chosen less often than steps with the default Weight of 4, so that more steps run before it.
+				Weight: 1,
+				Func: func() {
+					target.Reset()
+				},
+			},
+			{
+				Name: "Fuzz_Buffer_Write",
+				Func: func(p []byte) (int, error) {
+					return target.Write(p)
+				},
+			},
+			{
+				Name: "Fuzz_NewBuffer",
+				Func: func() *Buffer {
+					return NewBuffer()
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
@@ -0,0 +1,73 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_Counter_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		var target Counter
+		fz.Fill(&target)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Counter_Add",
+				Func: func(key string, n int) {
+					target.Add(key, n)
+				},
+			},
+			{
+				Name: "Fuzz_Counter_Get",
+				Func: func(key string) int {
+					return target.Get(key)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
+
+func Fuzz_Point_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		var target Point
+		fz.Fill(&target)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Point_Scale",
+				Func: func(k int) {
+					target.Scale(k)
+				},
+			},
+			{
+				Name: "Fuzz_Point_Add",
+				Func: func(q Point) Point {
+					return target.Add(q)
+				},
+			},
+			{
+				Name: "Fuzz_Point_String",
+				Func: func() string {
+					return target.String()
+				},
+			},
+			{
+				Name: "Fuzz_ManhattanDistance",
+				Func: func(q *Point) int {
+					return ManhattanDistance(&target, q)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
@@ -0,0 +1,72 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	fallbackexample "github.com/thepudds/fzgen/examples/inputs/test-chain-fallback"
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_Counter_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		var target fallbackexample.Counter
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Counter_Add",
+				Func: func(key string, n int) {
+					target.Add(key, n)
+				},
+			},
+			{
+				Name: "Fuzz_Counter_Get",
+				Func: func(key string) int {
+					return target.Get(key)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
+
+func Fuzz_Point_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		var target fallbackexample.Point
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Point_Scale",
+				Func: func(k int) {
+					target.Scale(k)
+				},
+			},
+			{
+				Name: "Fuzz_Point_Add",
+				Func: func(q fallbackexample.Point) fallbackexample.Point {
+					return target.Add(q)
+				},
+			},
+			{
+				Name: "Fuzz_Point_String",
+				Func: func() string {
+					return target.String()
+				},
+			},
+			{
+				Name: "Fuzz_ManhattanDistance",
+				Func: func(q *fallbackexample.Point) int {
+					return fallbackexample.ManhattanDistance(&target, q)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
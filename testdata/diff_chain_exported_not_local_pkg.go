@@ -0,0 +1,93 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	intset "github.com/thepudds/fzgen/examples/inputs/test-diff"
+	intsetref "github.com/thepudds/fzgen/examples/inputs/test-diff/sliceset"
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_Diff_New_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var max int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&max)
+
+		target, err := intset.New(max)
+		ref, refErr := intsetref.New(max)
+		fuzzer.CheckDiff("New", []interface{}{max}, []interface{}{err}, []interface{}{refErr})
+		if err != nil {
+			return
+		}
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Set_Add",
+				Func: func(x int) bool {
+					return target.Add(x)
+				},
+			},
+			{
+				Name: "Fuzz_Set_AddAll",
+				Func: func(xs []int) {
+					target.AddAll(xs...)
+				},
+			},
+			{
+				Name: "Fuzz_Set_Elems",
+				Func: func() []int {
+					return target.Elems()
+				},
+			},
+			{
+				Name: "Fuzz_Set_Has",
+				Func: func(x int) bool {
+					return target.Has(x)
+				},
+			},
+			{
+				Name: "Fuzz_Set_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+		}
+		refSteps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Set_Add",
+				Func: func(x int) bool {
+					return ref.Add(x)
+				},
+			},
+			{
+				Name: "Fuzz_Set_AddAll",
+				Func: func(xs []int) {
+					ref.AddAll(xs...)
+				},
+			},
+			{
+				Name: "Fuzz_Set_Elems",
+				Func: func() []int {
+					return ref.Elems()
+				},
+			},
+			{
+				Name: "Fuzz_Set_Has",
+				Func: func(x int) bool {
+					return ref.Has(x)
+				},
+			},
+			{
+				Name: "Fuzz_Set_Len",
+				Func: func() int {
+					return ref.Len()
+				},
+			},
+		}
+
+		// Execute the same chain of steps against target and ref, with the count, sequence and arguments
+		// controlled by fz.ChainDiff, which panics if the results of any step differ.
+		fz.ChainDiff(steps, refSteps)
+	})
+}
@@ -0,0 +1,31 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	intsetref "github.com/thepudds/fzgen/examples/inputs/test-diff/sliceset"
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_Diff_Parse(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		result1, result2 := Parse(s)
+		ref1, ref2 := intsetref.Parse(s)
+		fuzzer.CheckDiff("Parse", []interface{}{s}, []interface{}{result1, result2}, []interface{}{ref1, ref2})
+	})
+}
+
+func Fuzz_Diff_Sum(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var xs []int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&xs)
+		var xsRef []int
+		fzRef := fuzzer.NewFuzzer(data)
+		fzRef.Fill(&xsRef)
+
+		result1 := Sum(xs)
+		ref1 := intsetref.Sum(xsRef)
+		fuzzer.CheckDiff("Sum", []interface{}{xs}, []interface{}{result1}, []interface{}{ref1})
+	})
+}
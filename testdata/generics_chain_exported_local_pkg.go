@@ -0,0 +1,311 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_NewCache_int_bytes_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size)
+
+		target := NewCache[int, []byte](size)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Cache_Get",
+				Func: func(k int) ([]byte, bool) {
+					return target.Get(k)
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Put",
+				Func: func(k int, v []byte) {
+					target.Put(k, v)
+				},
+			},
+			{
+				Name: "Fuzz_NewCache_int_bytes",
+				Func: func(size int) *Cache[int, []byte] {
+					return NewCache[int, []byte](size)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
+
+func Fuzz_NewCache_int_int_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size)
+
+		target := NewCache[int, int](size)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Cache_Get",
+				Func: func(k int) (int, bool) {
+					return target.Get(k)
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Put",
+				Func: func(k int, v int) {
+					target.Put(k, v)
+				},
+			},
+			{
+				Name: "Fuzz_NewCache_int_int",
+				Func: func(size int) *Cache[int, int] {
+					return NewCache[int, int](size)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
+
+func Fuzz_NewCache_int_string_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size)
+
+		target := NewCache[int, string](size)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Cache_Get",
+				Func: func(k int) (string, bool) {
+					return target.Get(k)
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Put",
+				Func: func(k int, v string) {
+					target.Put(k, v)
+				},
+			},
+			{
+				Name: "Fuzz_NewCache_int_string",
+				Func: func(size int) *Cache[int, string] {
+					return NewCache[int, string](size)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
+
+func Fuzz_NewCache_string_bytes_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size)
+
+		target := NewCache[string, []byte](size)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Cache_Get",
+				Func: func(k string) ([]byte, bool) {
+					return target.Get(k)
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Put",
+				Func: func(k string, v []byte) {
+					target.Put(k, v)
+				},
+			},
+			{
+				Name: "Fuzz_NewCache_string_bytes",
+				Func: func(size int) *Cache[string, []byte] {
+					return NewCache[string, []byte](size)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
+
+func Fuzz_NewCache_string_int_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size)
+
+		target := NewCache[string, int](size)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Cache_Get",
+				Func: func(k string) (int, bool) {
+					return target.Get(k)
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Put",
+				Func: func(k string, v int) {
+					target.Put(k, v)
+				},
+			},
+			{
+				Name: "Fuzz_NewCache_string_int",
+				Func: func(size int) *Cache[string, int] {
+					return NewCache[string, int](size)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
+
+func Fuzz_NewCache_string_string_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size)
+
+		target := NewCache[string, string](size)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Cache_Get",
+				Func: func(k string) (string, bool) {
+					return target.Get(k)
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Put",
+				Func: func(k string, v string) {
+					target.Put(k, v)
+				},
+			},
+			{
+				Name: "Fuzz_NewCache_string_string",
+				Func: func(size int) *Cache[string, string] {
+					return NewCache[string, string](size)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
+
+func Fuzz_NewSet_int_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		target := NewSet[int]()
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Set_Add",
+				Func: func(v int) {
+					target.Add(v)
+				},
+			},
+			{
+				Name: "Fuzz_Set_Has",
+				Func: func(v int) bool {
+					return target.Has(v)
+				},
+			},
+			{
+				Name: "Fuzz_NewSet_int",
+				Func: func() *Set[int] {
+					return NewSet[int]()
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
+
+func Fuzz_NewSet_string_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		target := NewSet[string]()
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Set_Add",
+				Func: func(v string) {
+					target.Add(v)
+				},
+			},
+			{
+				Name: "Fuzz_Set_Has",
+				Func: func(v string) bool {
+					return target.Has(v)
+				},
+			},
+			{
+				Name: "Fuzz_NewSet_string",
+				Func: func() *Set[string] {
+					return NewSet[string]()
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps)
+	})
+}
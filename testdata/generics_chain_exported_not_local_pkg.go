@@ -0,0 +1,312 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	generics "github.com/thepudds/fzgen/examples/inputs/test-generics"
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_NewCache_int_bytes_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size)
+
+		target := generics.NewCache[int, []byte](size)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Cache_Get",
+				Func: func(k int) ([]byte, bool) {
+					return target.Get(k)
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Put",
+				Func: func(k int, v []byte) {
+					target.Put(k, v)
+				},
+			},
+			{
+				Name: "Fuzz_NewCache_int_bytes",
+				Func: func(size int) *generics.Cache[int, []byte] {
+					return generics.NewCache[int, []byte](size)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps, fuzzer.ChainParallel)
+	})
+}
+
+func Fuzz_NewCache_int_int_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size)
+
+		target := generics.NewCache[int, int](size)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Cache_Get",
+				Func: func(k int) (int, bool) {
+					return target.Get(k)
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Put",
+				Func: func(k int, v int) {
+					target.Put(k, v)
+				},
+			},
+			{
+				Name: "Fuzz_NewCache_int_int",
+				Func: func(size int) *generics.Cache[int, int] {
+					return generics.NewCache[int, int](size)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps, fuzzer.ChainParallel)
+	})
+}
+
+func Fuzz_NewCache_int_string_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size)
+
+		target := generics.NewCache[int, string](size)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Cache_Get",
+				Func: func(k int) (string, bool) {
+					return target.Get(k)
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Put",
+				Func: func(k int, v string) {
+					target.Put(k, v)
+				},
+			},
+			{
+				Name: "Fuzz_NewCache_int_string",
+				Func: func(size int) *generics.Cache[int, string] {
+					return generics.NewCache[int, string](size)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps, fuzzer.ChainParallel)
+	})
+}
+
+func Fuzz_NewCache_string_bytes_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size)
+
+		target := generics.NewCache[string, []byte](size)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Cache_Get",
+				Func: func(k string) ([]byte, bool) {
+					return target.Get(k)
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Put",
+				Func: func(k string, v []byte) {
+					target.Put(k, v)
+				},
+			},
+			{
+				Name: "Fuzz_NewCache_string_bytes",
+				Func: func(size int) *generics.Cache[string, []byte] {
+					return generics.NewCache[string, []byte](size)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps, fuzzer.ChainParallel)
+	})
+}
+
+func Fuzz_NewCache_string_int_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size)
+
+		target := generics.NewCache[string, int](size)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Cache_Get",
+				Func: func(k string) (int, bool) {
+					return target.Get(k)
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Put",
+				Func: func(k string, v int) {
+					target.Put(k, v)
+				},
+			},
+			{
+				Name: "Fuzz_NewCache_string_int",
+				Func: func(size int) *generics.Cache[string, int] {
+					return generics.NewCache[string, int](size)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps, fuzzer.ChainParallel)
+	})
+}
+
+func Fuzz_NewCache_string_string_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size)
+
+		target := generics.NewCache[string, string](size)
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Cache_Get",
+				Func: func(k string) (string, bool) {
+					return target.Get(k)
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Len",
+				Func: func() int {
+					return target.Len()
+				},
+			},
+			{
+				Name: "Fuzz_Cache_Put",
+				Func: func(k string, v string) {
+					target.Put(k, v)
+				},
+			},
+			{
+				Name: "Fuzz_NewCache_string_string",
+				Func: func(size int) *generics.Cache[string, string] {
+					return generics.NewCache[string, string](size)
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps, fuzzer.ChainParallel)
+	})
+}
+
+func Fuzz_NewSet_int_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		target := generics.NewSet[int]()
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Set_Add",
+				Func: func(v int) {
+					target.Add(v)
+				},
+			},
+			{
+				Name: "Fuzz_Set_Has",
+				Func: func(v int) bool {
+					return target.Has(v)
+				},
+			},
+			{
+				Name: "Fuzz_NewSet_int",
+				Func: func() *generics.Set[int] {
+					return generics.NewSet[int]()
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps, fuzzer.ChainParallel)
+	})
+}
+
+func Fuzz_NewSet_string_Chain(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fz := fuzzer.NewFuzzer(data)
+
+		target := generics.NewSet[string]()
+
+		steps := []fuzzer.Step{
+			{
+				Name: "Fuzz_Set_Add",
+				Func: func(v string) {
+					target.Add(v)
+				},
+			},
+			{
+				Name: "Fuzz_Set_Has",
+				Func: func(v string) bool {
+					return target.Has(v)
+				},
+			},
+			{
+				Name: "Fuzz_NewSet_string",
+				Func: func() *generics.Set[string] {
+					return generics.NewSet[string]()
+				},
+			},
+		}
+
+		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
+		fz.Chain(steps, fuzzer.ChainParallel)
+	})
+}
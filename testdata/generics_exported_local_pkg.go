@@ -0,0 +1,333 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_Cache_Get_int_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k int) {
+		c := NewCache[int, int](size)
+		c.Get(k)
+	})
+}
+
+func Fuzz_Cache_Get_int_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k int) {
+		c := NewCache[int, string](size)
+		c.Get(k)
+	})
+}
+
+func Fuzz_Cache_Get_int_bytes(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k int) {
+		c := NewCache[int, []byte](size)
+		c.Get(k)
+	})
+}
+
+func Fuzz_Cache_Get_string_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k string) {
+		c := NewCache[string, int](size)
+		c.Get(k)
+	})
+}
+
+func Fuzz_Cache_Get_string_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k string) {
+		c := NewCache[string, string](size)
+		c.Get(k)
+	})
+}
+
+func Fuzz_Cache_Get_string_bytes(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k string) {
+		c := NewCache[string, []byte](size)
+		c.Get(k)
+	})
+}
+
+func Fuzz_Cache_Len_int_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		c := NewCache[int, int](size)
+		c.Len()
+	})
+}
+
+func Fuzz_Cache_Len_int_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		c := NewCache[int, string](size)
+		c.Len()
+	})
+}
+
+func Fuzz_Cache_Len_int_bytes(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		c := NewCache[int, []byte](size)
+		c.Len()
+	})
+}
+
+func Fuzz_Cache_Len_string_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		c := NewCache[string, int](size)
+		c.Len()
+	})
+}
+
+func Fuzz_Cache_Len_string_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		c := NewCache[string, string](size)
+		c.Len()
+	})
+}
+
+func Fuzz_Cache_Len_string_bytes(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		c := NewCache[string, []byte](size)
+		c.Len()
+	})
+}
+
+func Fuzz_Cache_Put_int_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k int, v int) {
+		c := NewCache[int, int](size)
+		c.Put(k, v)
+	})
+}
+
+func Fuzz_Cache_Put_int_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k int, v string) {
+		c := NewCache[int, string](size)
+		c.Put(k, v)
+	})
+}
+
+func Fuzz_Cache_Put_int_bytes(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k int, v []byte) {
+		c := NewCache[int, []byte](size)
+		c.Put(k, v)
+	})
+}
+
+func Fuzz_Cache_Put_string_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k string, v int) {
+		c := NewCache[string, int](size)
+		c.Put(k, v)
+	})
+}
+
+func Fuzz_Cache_Put_string_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k string, v string) {
+		c := NewCache[string, string](size)
+		c.Put(k, v)
+	})
+}
+
+func Fuzz_Cache_Put_string_bytes(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k string, v []byte) {
+		c := NewCache[string, []byte](size)
+		c.Put(k, v)
+	})
+}
+
+func Fuzz_Set_Add_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, v int) {
+		s := NewSet[int]()
+		s.Add(v)
+	})
+}
+
+func Fuzz_Set_Add_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, v string) {
+		s := NewSet[string]()
+		s.Add(v)
+	})
+}
+
+func Fuzz_Set_Has_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, v int) {
+		s := NewSet[int]()
+		s.Has(v)
+	})
+}
+
+func Fuzz_Set_Has_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, v string) {
+		s := NewSet[string]()
+		s.Has(v)
+	})
+}
+
+func Fuzz_Index_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var items []int
+		var t2 int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&items, &t2)
+
+		Index[int](items, t2)
+	})
+}
+
+func Fuzz_Index_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var items []string
+		var t2 string
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&items, &t2)
+
+		Index[string](items, t2)
+	})
+}
+
+// skipping Fuzz_Map_int_int because parameters include func, chan, or unsupported interface: func(int) int
+
+// skipping Fuzz_Map_int_string because parameters include func, chan, or unsupported interface: func(int) string
+
+// skipping Fuzz_Map_int_bytes because parameters include func, chan, or unsupported interface: func(int) []byte
+
+// skipping Fuzz_Map_string_int because parameters include func, chan, or unsupported interface: func(string) int
+
+// skipping Fuzz_Map_string_string because parameters include func, chan, or unsupported interface: func(string) string
+
+// skipping Fuzz_Map_string_bytes because parameters include func, chan, or unsupported interface: func(string) []byte
+
+// skipping Fuzz_Map_bytes_int because parameters include func, chan, or unsupported interface: func([]byte) int
+
+// skipping Fuzz_Map_bytes_string because parameters include func, chan, or unsupported interface: func([]byte) string
+
+// skipping Fuzz_Map_bytes_bytes because parameters include func, chan, or unsupported interface: func([]byte) []byte
+
+func Fuzz_NewCache_int_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		NewCache[int, int](size)
+	})
+}
+
+func Fuzz_NewCache_int_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		NewCache[int, string](size)
+	})
+}
+
+func Fuzz_NewCache_int_bytes(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		NewCache[int, []byte](size)
+	})
+}
+
+func Fuzz_NewCache_string_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		NewCache[string, int](size)
+	})
+}
+
+func Fuzz_NewCache_string_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		NewCache[string, string](size)
+	})
+}
+
+func Fuzz_NewCache_string_bytes(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		NewCache[string, []byte](size)
+	})
+}
+
+func Fuzz_Pair_int_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, k int, v int) {
+		Pair[int, int](k, v)
+	})
+}
+
+func Fuzz_Pair_int_int64(f *testing.F) {
+	f.Fuzz(func(t *testing.T, k int, v int64) {
+		Pair[int, int64](k, v)
+	})
+}
+
+func Fuzz_Pair_int_float64(f *testing.F) {
+	f.Fuzz(func(t *testing.T, k int, v float64) {
+		Pair[int, float64](k, v)
+	})
+}
+
+func Fuzz_Pair_string_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, k string, v int) {
+		Pair[string, int](k, v)
+	})
+}
+
+func Fuzz_Pair_string_int64(f *testing.F) {
+	f.Fuzz(func(t *testing.T, k string, v int64) {
+		Pair[string, int64](k, v)
+	})
+}
+
+func Fuzz_Pair_string_float64(f *testing.F) {
+	f.Fuzz(func(t *testing.T, k string, v float64) {
+		Pair[string, float64](k, v)
+	})
+}
+
+func Fuzz_Reverse_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s []int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&s)
+
+		Reverse[int](s)
+	})
+}
+
+func Fuzz_Reverse_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s []string
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&s)
+
+		Reverse[string](s)
+	})
+}
+
+func Fuzz_Reverse_bytes(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s [][]byte
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&s)
+
+		Reverse[[]byte](s)
+	})
+}
+
+func Fuzz_Sum_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var nums []int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&nums)
+
+		Sum[int](nums)
+	})
+}
+
+func Fuzz_Sum_int64(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var nums []int64
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&nums)
+
+		Sum[int64](nums)
+	})
+}
+
+func Fuzz_Sum_float64(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var nums []float64
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&nums)
+
+		Sum[float64](nums)
+	})
+}
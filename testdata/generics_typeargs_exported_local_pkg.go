@@ -0,0 +1,159 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_Cache_Get_uint8_uint8(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k uint8) {
+		c := NewCache[uint8, uint8](size)
+		c.Get(k)
+	})
+}
+
+func Fuzz_Cache_Get_uint8_slice_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k uint8) {
+		c := NewCache[uint8, []string](size)
+		c.Get(k)
+	})
+}
+
+func Fuzz_Cache_Len_uint8_uint8(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		c := NewCache[uint8, uint8](size)
+		c.Len()
+	})
+}
+
+func Fuzz_Cache_Len_uint8_slice_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		c := NewCache[uint8, []string](size)
+		c.Len()
+	})
+}
+
+func Fuzz_Cache_Put_uint8_uint8(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int, k uint8, v uint8) {
+		c := NewCache[uint8, uint8](size)
+		c.Put(k, v)
+	})
+}
+
+func Fuzz_Cache_Put_uint8_slice_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var size int
+		var k uint8
+		var v []string
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&size, &k, &v)
+
+		c := NewCache[uint8, []string](size)
+		c.Put(k, v)
+	})
+}
+
+func Fuzz_Set_Add_uint8(f *testing.F) {
+	f.Fuzz(func(t *testing.T, v uint8) {
+		s := NewSet[uint8]()
+		s.Add(v)
+	})
+}
+
+func Fuzz_Set_Has_uint8(f *testing.F) {
+	f.Fuzz(func(t *testing.T, v uint8) {
+		s := NewSet[uint8]()
+		s.Has(v)
+	})
+}
+
+func Fuzz_Index_uint8(f *testing.F) {
+	f.Fuzz(func(t *testing.T, items []uint8, t2 uint8) {
+		Index[uint8](items, t2)
+	})
+}
+
+// skipping Fuzz_Map_uint8_uint8 because parameters include func, chan, or unsupported interface: func(uint8) uint8
+
+// skipping Fuzz_Map_uint8_slice_string because parameters include func, chan, or unsupported interface: func(uint8) []string
+
+// skipping Fuzz_Map_slice_string_uint8 because parameters include func, chan, or unsupported interface: func([]string) uint8
+
+// skipping Fuzz_Map_slice_string_slice_string because parameters include func, chan, or unsupported interface: func([]string) []string
+
+func Fuzz_NewCache_uint8_uint8(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		NewCache[uint8, uint8](size)
+	})
+}
+
+func Fuzz_NewCache_uint8_slice_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, size int) {
+		NewCache[uint8, []string](size)
+	})
+}
+
+func Fuzz_Pair_uint8_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, k uint8, v int) {
+		Pair[uint8, int](k, v)
+	})
+}
+
+func Fuzz_Pair_uint8_int64(f *testing.F) {
+	f.Fuzz(func(t *testing.T, k uint8, v int64) {
+		Pair[uint8, int64](k, v)
+	})
+}
+
+func Fuzz_Pair_uint8_float64(f *testing.F) {
+	f.Fuzz(func(t *testing.T, k uint8, v float64) {
+		Pair[uint8, float64](k, v)
+	})
+}
+
+func Fuzz_Reverse_uint8(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s []uint8) {
+		Reverse[uint8](s)
+	})
+}
+
+func Fuzz_Reverse_slice_string(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s [][]string
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&s)
+
+		Reverse[[]string](s)
+	})
+}
+
+func Fuzz_Sum_int(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var nums []int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&nums)
+
+		Sum[int](nums)
+	})
+}
+
+func Fuzz_Sum_int64(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var nums []int64
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&nums)
+
+		Sum[int64](nums)
+	})
+}
+
+func Fuzz_Sum_float64(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var nums []float64
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&nums)
+
+		Sum[float64](nums)
+	})
+}
@@ -0,0 +1,156 @@
+package examplefuzz
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	inverse "github.com/thepudds/fzgen/examples/inputs/test-inverse"
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_Decode(f *testing.F) {
+	f.Fuzz(func(t *testing.T, dst []byte, src []byte, flush bool) {
+		inverse.Decode(dst, src, flush)
+	})
+}
+
+func Fuzz_Encode(f *testing.F) {
+	f.Fuzz(func(t *testing.T, dst []byte, src []byte) {
+		inverse.Encode(dst, src)
+	})
+}
+
+func Fuzz_Escape(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		inverse.Escape(s)
+	})
+}
+
+func Fuzz_FormatPoint(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var p inverse.Point
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&p)
+
+		inverse.FormatPoint(p)
+	})
+}
+
+func Fuzz_MarshalPoint(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var p inverse.Point
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&p)
+
+		inverse.MarshalPoint(p)
+	})
+}
+
+func Fuzz_MaxEncodedLen(f *testing.F) {
+	f.Fuzz(func(t *testing.T, n int) {
+		inverse.MaxEncodedLen(n)
+	})
+}
+
+func Fuzz_ParsePoint(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		inverse.ParsePoint(s)
+	})
+}
+
+func Fuzz_Quote(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		inverse.Quote(s)
+	})
+}
+
+func Fuzz_UnmarshalPoint(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var b []byte
+		var p *inverse.Point
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&b, &p)
+		if p == nil {
+			return
+		}
+
+		inverse.UnmarshalPoint(b, p)
+	})
+}
+
+func Fuzz_Unquote(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		inverse.Unquote(s)
+	})
+}
+
+func Fuzz_Encode_Decode_Roundtrip(f *testing.F) {
+	f.Fuzz(func(t *testing.T, src []byte) {
+		encoded := make([]byte, inverse.MaxEncodedLen(len(src)))
+		encoded = encoded[:inverse.Encode(encoded, src)]
+
+		decoded := make([]byte, 4*len(encoded))
+		ndst, _, err := inverse.Decode(decoded, encoded, true)
+		if err != nil {
+			panic(fmt.Sprintf("Decode failed after successful Encode. original: %#v encoded: %#v error: %v", src, encoded, err))
+		}
+		if !bytes.Equal(src, decoded[:ndst]) {
+			panic(fmt.Sprintf("Encode/Decode roundtrip mismatch. original: %#v encoded: %#v decoded: %#v", src, encoded, decoded[:ndst]))
+		}
+	})
+}
+
+func Fuzz_FormatPoint_ParsePoint_Roundtrip(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var p inverse.Point
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&p)
+
+		encoded := inverse.FormatPoint(p)
+
+		decoded, err := inverse.ParsePoint(encoded)
+		if err != nil {
+			panic(fmt.Sprintf("ParsePoint failed after successful FormatPoint. original: %#v encoded: %#v error: %v", p, encoded, err))
+		}
+		if diff := fuzzer.DiffRoundtrip(p, decoded); diff != "" {
+			panic(fmt.Sprintf("FormatPoint/ParsePoint roundtrip mismatch (-original +decoded):\n%s\noriginal: %#v encoded: %#v decoded: %#v", diff, p, encoded, decoded))
+		}
+	})
+}
+
+func Fuzz_MarshalPoint_UnmarshalPoint_Roundtrip(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var p inverse.Point
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&p)
+
+		encoded, err := inverse.MarshalPoint(p)
+		if err != nil {
+			return
+		}
+
+		var decoded inverse.Point
+		err = inverse.UnmarshalPoint(encoded, &decoded)
+		if err != nil {
+			panic(fmt.Sprintf("UnmarshalPoint failed after successful MarshalPoint. original: %#v encoded: %#v error: %v", p, encoded, err))
+		}
+		if diff := fuzzer.DiffRoundtrip(p, decoded); diff != "" {
+			panic(fmt.Sprintf("MarshalPoint/UnmarshalPoint roundtrip mismatch (-original +decoded):\n%s\noriginal: %#v encoded: %#v decoded: %#v", diff, p, encoded, decoded))
+		}
+	})
+}
+
+func Fuzz_Quote_Unquote_Roundtrip(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		encoded := inverse.Quote(s)
+
+		decoded, err := inverse.Unquote(encoded)
+		if err != nil {
+			panic(fmt.Sprintf("Unquote failed after successful Quote. original: %#v encoded: %#v error: %v", s, encoded, err))
+		}
+		if diff := fuzzer.DiffRoundtrip(s, decoded); diff != "" {
+			panic(fmt.Sprintf("Quote/Unquote roundtrip mismatch (-original +decoded):\n%s\noriginal: %#v encoded: %#v decoded: %#v", diff, s, encoded, decoded))
+		}
+	})
+}
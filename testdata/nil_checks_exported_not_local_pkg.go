@@ -33,6 +33,12 @@ func Fuzz_NewTypesNilCheck_Chain(f *testing.F) {
 					return target.WriteTo(stream)
 				},
 			},
+			{
+				Name: "Fuzz_NewTypesNilCheck",
+				Func: func() *fuzzwrapexamples.TypesNilCheck {
+					return fuzzwrapexamples.NewTypesNilCheck()
+				},
+			},
 		}
 
 		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
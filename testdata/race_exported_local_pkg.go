@@ -25,6 +25,12 @@ func Fuzz_NewMySafeMap_Chain(f *testing.F) {
 					target.Store(key, req)
 				},
 			},
+			{
+				Name: "Fuzz_NewMySafeMap",
+				Func: func() *MySafeMap {
+					return NewMySafeMap()
+				},
+			},
 		}
 
 		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
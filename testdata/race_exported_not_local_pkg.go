@@ -26,6 +26,12 @@ func Fuzz_NewMySafeMap_Chain(f *testing.F) {
 					target.Store(key, req)
 				},
 			},
+			{
+				Name: "Fuzz_NewMySafeMap",
+				Func: func() *raceexample.MySafeMap {
+					return raceexample.NewMySafeMap()
+				},
+			},
 		}
 
 		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
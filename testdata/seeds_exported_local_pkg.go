@@ -0,0 +1,61 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_Buffer_Grow(f *testing.F) {
+	f.Add(int(8), int(0))
+	f.Fuzz(func(t *testing.T, limit int, n int) {
+		b := NewBuffer(limit)
+		b.Grow(n)
+	})
+}
+
+func Fuzz_Buffer_Write(f *testing.F) {
+	f.Add(int(8), []byte("hello"))
+	f.Fuzz(func(t *testing.T, limit int, p []byte) {
+		b := NewBuffer(limit)
+		b.Write(p)
+	})
+}
+
+func Fuzz_Clamp(f *testing.F) {
+	f.Add(int64(-5), int64(0), int64(10), bool(false))
+	f.Add(int64(11), int64(0), int64(10), bool(true))
+	f.Fuzz(func(t *testing.T, n int64, lo int64, hi int64, wrap bool) {
+		Clamp(n, lo, hi, wrap)
+	})
+}
+
+func Fuzz_Match(f *testing.F) {
+	f.Add([]byte("\x00\x02lo\x05hello\x00\x00"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var pattern string
+		var b []byte
+		var n uint16
+		var counts map[string]int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&pattern, &b, &n, &counts)
+
+		Match(pattern, b, n, counts)
+	})
+}
+
+func Fuzz_NewBuffer(f *testing.F) {
+	f.Add(int(8))
+	f.Fuzz(func(t *testing.T, limit int) {
+		NewBuffer(limit)
+	})
+}
+
+func Fuzz_Parse(f *testing.F) {
+	f.Add(string("v0.9.1"))
+	f.Add(string("1.20.3"))
+	f.Add(string("1.2"))
+	f.Fuzz(func(t *testing.T, s string) {
+		Parse(s)
+	})
+}
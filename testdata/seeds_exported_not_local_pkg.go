@@ -0,0 +1,62 @@
+package examplefuzz
+
+import (
+	"testing"
+
+	seeds "github.com/thepudds/fzgen/examples/inputs/test-seeds"
+	"github.com/thepudds/fzgen/fuzzer"
+)
+
+func Fuzz_Buffer_Grow(f *testing.F) {
+	f.Add(int(8), int(0))
+	f.Fuzz(func(t *testing.T, limit int, n int) {
+		b := seeds.NewBuffer(limit)
+		b.Grow(n)
+	})
+}
+
+func Fuzz_Buffer_Write(f *testing.F) {
+	f.Add(int(8), []byte("hello"))
+	f.Fuzz(func(t *testing.T, limit int, p []byte) {
+		b := seeds.NewBuffer(limit)
+		b.Write(p)
+	})
+}
+
+func Fuzz_Clamp(f *testing.F) {
+	f.Add(int64(-5), int64(0), int64(10), bool(false))
+	f.Add(int64(11), int64(0), int64(10), bool(true))
+	f.Fuzz(func(t *testing.T, n int64, lo int64, hi int64, wrap bool) {
+		seeds.Clamp(n, lo, hi, wrap)
+	})
+}
+
+func Fuzz_Match(f *testing.F) {
+	f.Add([]byte("\x00\x02lo\x05hello\x00\x00"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var pattern string
+		var b []byte
+		var n uint16
+		var counts map[string]int
+		fz := fuzzer.NewFuzzer(data)
+		fz.Fill(&pattern, &b, &n, &counts)
+
+		seeds.Match(pattern, b, n, counts)
+	})
+}
+
+func Fuzz_NewBuffer(f *testing.F) {
+	f.Add(int(8))
+	f.Fuzz(func(t *testing.T, limit int) {
+		seeds.NewBuffer(limit)
+	})
+}
+
+func Fuzz_Parse(f *testing.F) {
+	f.Add(string("v0.9.1"))
+	f.Add(string("1.20.3"))
+	f.Add(string("1.2"))
+	f.Fuzz(func(t *testing.T, s string) {
+		seeds.Parse(s)
+	})
+}
@@ -2,7 +2,6 @@ package examplefuzz
 
 import (
 	"fmt"
-	"reflect"
 	"testing"
 
 	uuid "github.com/thepudds/fzgen/examples/inputs/test-chain-uuid"
@@ -23,8 +22,8 @@ func Fuzz_NewFromBytes_Chain(f *testing.F) {
 		steps := []fuzzer.Step{
 			{
 				Name: "Fuzz_MyUUID_UnmarshalBinary",
-				Func: func(d1 []byte) {
-					target.UnmarshalBinary(d1)
+				Func: func(d1 []byte) error {
+					return target.UnmarshalBinary(d1)
 				},
 			},
 			{
@@ -39,29 +38,31 @@ func Fuzz_NewFromBytes_Chain(f *testing.F) {
 					return target.URN()
 				},
 			},
+			{
+				Name: "Fuzz_NewFromBytes",
+				Func: func(b []byte) (uuid.MyUUID, error) {
+					return uuid.NewFromBytes(b)
+				},
+			},
 		}
 
 		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain
 		fz.Chain(steps, fuzzer.ChainParallel)
 
 		// Validate with some roundtrip checks. These can be edited or deleted if not appropriate for your target.
-		// Check MarshalBinary.
-		result2, err := target.MarshalBinary()
-		if err != nil {
-			// Some targets should never return an error here for an object created by a constructor.
-			// If that is the case for your target, you can change this to a panic(err) or t.Fatal.
-			return
-		}
-
-		// Check UnmarshalBinary.
-		var tmp2 uuid.MyUUID
-		err = tmp2.UnmarshalBinary(result2)
-		if err != nil {
-			panic(fmt.Sprintf("UnmarshalBinary failed after successful MarshalBinary. original: %v %#v marshalled: %q error: %v", target, target, result2, err))
-		}
-		if !reflect.DeepEqual(target, tmp2) {
-			panic(fmt.Sprintf("MarshalBinary/UnmarshalBinary roundtrip equality failed. original: %v %#v marshalled: %q unmarshalled: %v %#v",
-				target, target, result2, tmp2, tmp2))
+		// Check MarshalBinary and UnmarshalBinary.
+		// Some targets should never return an error from MarshalBinary for an object created by a constructor.
+		// If that is the case for your target, you can add an else branch that calls panic(err) or t.Fatal.
+		if result2, err := target.MarshalBinary(); err == nil {
+			var tmp2 uuid.MyUUID
+			err = tmp2.UnmarshalBinary(result2)
+			if err != nil {
+				panic(fmt.Sprintf("UnmarshalBinary failed after successful MarshalBinary. original: %v %#v marshalled: %q error: %v", target, target, result2, err))
+			}
+			if diff := fuzzer.DiffRoundtrip(target, tmp2); diff != "" {
+				panic(fmt.Sprintf("MarshalBinary/UnmarshalBinary roundtrip equality failed (-original +unmarshalled):\n%s\noriginal: %v %#v marshalled: %q unmarshalled: %v %#v",
+					diff, target, target, result2, tmp2, tmp2))
+			}
 		}
 	})
 }
@@ -75,8 +76,8 @@ func Fuzz_NewMyUUID2_Chain(f *testing.F) {
 		steps := []fuzzer.Step{
 			{
 				Name: "Fuzz_MyUUID2_Bar",
-				Func: func(d1 []byte) {
-					target.Bar(d1)
+				Func: func(d1 []byte) error {
+					return target.Bar(d1)
 				},
 			},
 			{
@@ -85,6 +86,12 @@ func Fuzz_NewMyUUID2_Chain(f *testing.F) {
 					return target.Foo()
 				},
 			},
+			{
+				Name: "Fuzz_NewMyUUID2",
+				Func: func() *uuid.MyUUID2 {
+					return uuid.NewMyUUID2()
+				},
+			},
 		}
 
 		// Execute a specific chain of steps, with the count, sequence and arguments controlled by fz.Chain